@@ -0,0 +1,99 @@
+package z
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Alias is a named shortcut for a project/task pair, used as '@name' instead of --project/--task/--notes.
+type Alias struct {
+	Name    string
+	Project string
+	Task    string
+	Notes   string
+}
+
+// ErrAliasExists is returned when an alias is added under a name that is already taken.
+var ErrAliasExists = errors.New("an alias with this name exists already")
+
+// IsAlias reports if value refers to an alias, like '@standup'.
+func IsAlias(value string) bool {
+	return strings.HasPrefix(value, "@")
+}
+
+// aliasName is the name of the alias value refers to, without the '@'.
+func aliasName(value string) string {
+	return strings.TrimPrefix(value, "@")
+}
+
+// AddAlias stores the alias, replacing one with the same name only if replace is set.
+func (db *Database) AddAlias(alias Alias, replace bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	query := `INSERT INTO aliases(name, project, task, notes) VALUES(?, ?, ?, ?);`
+	if replace {
+		query = `INSERT OR REPLACE INTO aliases(name, project, task, notes) VALUES(?, ?, ?, ?);`
+	}
+	_, err := db.DB.ExecContext(ctx, query, alias.Name, alias.Project, alias.Task, alias.Notes)
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrConstraint {
+		return ErrAliasExists
+	}
+	return err
+}
+
+// GetAlias returns the alias with the given name, sql.ErrNoRows if there is none.
+func (db *Database) GetAlias(name string) (*Alias, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	var alias Alias
+	err := db.DB.QueryRowContext(ctx, `SELECT name, project, task, notes FROM aliases WHERE name = ?;`, name).
+		Scan(&alias.Name, &alias.Project, &alias.Task, &alias.Notes)
+	if err != nil {
+		return nil, err
+	}
+	return &alias, nil
+}
+
+func (db *Database) GetAliases() ([]Alias, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	rows, err := db.DB.QueryContext(ctx, `SELECT name, project, task, notes FROM aliases ORDER BY name;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var aliases []Alias
+	for rows.Next() {
+		var alias Alias
+		err := rows.Scan(&alias.Name, &alias.Project, &alias.Task, &alias.Notes)
+		if err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, alias)
+	}
+	return aliases, rows.Err()
+}
+
+// DeleteAlias removes the alias, sql.ErrNoRows if there is none with that name.
+func (db *Database) DeleteAlias(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	result, err := db.DB.ExecContext(ctx, `DELETE FROM aliases WHERE name = ?;`, name)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
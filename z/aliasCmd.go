@@ -0,0 +1,169 @@
+package z
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+var aliasNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage shortcuts for projects and tasks",
+	Long: `Manage named shortcuts for project/task pairs that are tracked often.
+
+An alias is used as '@name' in place of --project, --task and --notes:
+  zeit track @standup
+  zeit switch @standup
+  zeit list --project @standup
+Flags given next to an alias win over what the alias stores.`,
+}
+
+var aliasAddCmd = &cobra.Command{
+	Use:   "add [name]",
+	Short: "Add an alias",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := aliasName(args[0])
+		if !aliasNamePattern.MatchString(name) {
+			fmt.Printf("%s an alias name may only contain letters, digits, '_', '.' and '-'.\n", CharError)
+			os.Exit(1)
+		}
+		if project == "" {
+			fmt.Printf("%s Can not add an alias without a project.\nPlease assign a project via --project\n", CharError)
+			os.Exit(1)
+		}
+		alias := Alias{Name: name, Project: project, Task: task, Notes: notes}
+		err := database.AddAlias(alias, force)
+		if errors.Is(err, ErrAliasExists) {
+			fmt.Printf("%s @%s exists already, use --force to replace it.\n", CharError, name)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s added %s\n", CharInfo, getOutputForAlias(alias))
+	},
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all aliases",
+	Run: func(cmd *cobra.Command, args []string) {
+		aliases, err := database.GetAliases()
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		if len(aliases) == 0 {
+			fmt.Printf("%s no aliases yet, add one with 'zeit alias add'\n", CharInfo)
+			return
+		}
+		for _, alias := range aliases {
+			fmt.Printf("%s %s\n", CharMore, getOutputForAlias(alias))
+		}
+	},
+}
+
+var aliasRemoveCmd = &cobra.Command{
+	Use:   "remove [name]",
+	Short: "Remove an alias",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := aliasName(args[0])
+		err := database.DeleteAlias(name)
+		if errors.Is(err, sql.ErrNoRows) {
+			fmt.Printf("%s there is no alias @%s.\n", CharError, name)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s removed %s\n", CharErase, color.FgLightWhite.Render("@"+name))
+	},
+}
+
+func getOutputForAlias(alias Alias) string {
+	output := fmt.Sprintf("%s: %s", color.FgLightWhite.Render("@"+alias.Name), color.FgLightWhite.Render(alias.Project))
+	if alias.Task != "" {
+		output = fmt.Sprintf("%s / %s", output, color.FgLightWhite.Render(alias.Task))
+	}
+	if alias.Notes != "" {
+		output = fmt.Sprintf("%s (%s)", output, alias.Notes)
+	}
+	return output
+}
+
+// lookupAlias returns the alias value refers to and stops zeit if there is none.
+func lookupAlias(value string) Alias {
+	alias, err := database.GetAlias(aliasName(value))
+	if errors.Is(err, sql.ErrNoRows) {
+		fmt.Printf("%s there is no alias %s, see 'zeit alias list'.\n", CharError, value)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+	return *alias
+}
+
+// applyAliasArg fills --project, --task and --notes from the alias given as the only argument,
+// flags that were given explicitly are kept.
+func applyAliasArg(args []string) {
+	if len(args) == 0 {
+		return
+	}
+	if !IsAlias(args[0]) {
+		fmt.Printf("%s '%s' is not an alias, aliases start with '@' like @standup.\n", CharError, args[0])
+		os.Exit(1)
+	}
+	alias := lookupAlias(args[0])
+	if project == "" {
+		project = alias.Project
+	}
+	if task == "" {
+		task = alias.Task
+	}
+	if notes == "" {
+		notes = alias.Notes
+	}
+}
+
+// expandProjectFilter turns '--project @name' into the project, and the task unless --task is given, of the alias.
+func expandProjectFilter() {
+	if !IsAlias(project) {
+		return
+	}
+	alias := lookupAlias(project)
+	project = alias.Project
+	if task == "" {
+		task = alias.Task
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(aliasCmd)
+	aliasCmd.AddCommand(aliasAddCmd)
+	aliasCmd.AddCommand(aliasListCmd)
+	aliasCmd.AddCommand(aliasRemoveCmd)
+	aliasAddCmd.Flags().StringVarP(&project, "project", "p", "", "Project the alias stands for")
+	aliasAddCmd.Flags().StringVarP(&task, "task", "t", "", "Task the alias stands for")
+	aliasAddCmd.Flags().StringVarP(&notes, "notes", "n", "", "Notes the alias adds")
+	aliasAddCmd.Flags().BoolVar(&force, "force", false, "Replace an existing alias with the same name")
+
+	var err error
+	database, err = InitDB()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,148 @@
+package z
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Billing states of an entry. An entry is invoiced by 'zeit invoice' and released again when the invoice is voided.
+const (
+	BillingUnbilled   = "unbilled"
+	BillingInvoiced   = "invoiced"
+	BillingWrittenOff = "written-off"
+)
+
+var ErrInvoiceVoided = errors.New("the invoice is voided already")
+
+// ErrEntriesBilled means entries were billed by someone else while an invoice was written.
+var ErrEntriesBilled = errors.New("some of the activities were billed in the meantime")
+
+// InvoiceRecord is what is kept of an invoice once it is written.
+type InvoiceRecord struct {
+	Number   string
+	Project  string
+	Issued   time.Time
+	Currency string
+	Total    decimal.Decimal
+	Entries  int64
+	Voided   bool
+}
+
+// markInvoiced marks the entries as billed by the invoice, all of them have to be unbilled still.
+func markInvoiced(ctx context.Context, tx execer, number string, entries []Entry) error {
+	for _, entry := range entries {
+		result, err := tx.ExecContext(ctx, `UPDATE entries SET billing_state = ?, invoice_number = ? WHERE id = ? AND billing_state = ?;`,
+			BillingInvoiced, number, entry.ID, BillingUnbilled)
+		if err != nil {
+			return err
+		}
+		err = errIfNoneAffected(result)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEntriesBilled
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importBilling releases an imported entry that was invoiced on an invoice this database does not have
+// or has voided, so it is neither locked by an invoice nobody can void here nor left out of the next one.
+// Written off entries stay written off.
+func importBilling(entry *Entry, invoices []InvoiceRecord) {
+	if entry.BillingState == BillingWrittenOff {
+		entry.InvoiceNumber = ""
+		return
+	}
+	if entry.BillingState == BillingInvoiced {
+		for _, invoice := range invoices {
+			if invoice.Number == entry.InvoiceNumber && !invoice.Voided {
+				return
+			}
+		}
+	}
+	entry.BillingState = BillingUnbilled
+	entry.InvoiceNumber = ""
+}
+
+func (db *Database) GetInvoices() ([]InvoiceRecord, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	rows, err := db.DB.QueryContext(ctx, `SELECT number, project, issued, currency, total, voided IS NOT NULL,
+			(SELECT COUNT(*) FROM entries WHERE invoice_number = invoices.number)
+		FROM invoices ORDER BY issued, number;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var invoices []InvoiceRecord
+	for rows.Next() {
+		var invoice InvoiceRecord
+		var issued, total string
+		err := rows.Scan(&invoice.Number, &invoice.Project, &issued, &invoice.Currency, &total, &invoice.Voided, &invoice.Entries)
+		if err != nil {
+			return nil, err
+		}
+		invoice.Issued, err = time.Parse(time.RFC3339, issued)
+		if err != nil {
+			return nil, err
+		}
+		invoice.Total, err = decimal.NewFromString(total)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, invoice)
+	}
+	return invoices, rows.Err()
+}
+
+// VoidInvoice voids the invoice and releases its entries to be billed again. The number stays taken.
+// It returns sql.ErrNoRows if there is no such invoice and ErrInvoiceVoided if it is voided already.
+func (db *Database) VoidInvoice(number string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var voided bool
+	err = tx.QueryRowContext(ctx, `SELECT voided IS NOT NULL FROM invoices WHERE number = ?;`, number).Scan(&voided)
+	if err != nil {
+		return 0, err
+	}
+	if voided {
+		return 0, ErrInvoiceVoided
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE invoices SET voided = ? WHERE number = ?;`, time.Now().Format(time.RFC3339), number)
+	if err != nil {
+		return 0, err
+	}
+	result, err := tx.ExecContext(ctx, `UPDATE entries SET billing_state = ?, invoice_number = NULL WHERE invoice_number = ?;`, BillingUnbilled, number)
+	if err != nil {
+		return 0, err
+	}
+	released, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return released, tx.Commit()
+}
+
+// getOutputForBilling marks invoiced and written-off entries in lists, it is empty for unbilled ones.
+func getOutputForBilling(entry Entry) string {
+	switch entry.BillingState {
+	case BillingInvoiced:
+		return fmt.Sprintf("[invoiced %s]", entry.InvoiceNumber)
+	case BillingWrittenOff:
+		return "[written off]"
+	}
+	return ""
+}
@@ -0,0 +1,275 @@
+package z
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jinzhu/now"
+	"github.com/shopspring/decimal"
+)
+
+// Periods a budget is given for, set via 'zeit budget set --period'.
+const (
+	BudgetTotal = "total"
+	BudgetMonth = "month"
+)
+
+// budgetThresholds are the percentages of a budget that are warned about once they are crossed.
+var budgetThresholds = []int64{80, 100}
+
+// Project is what zeit knows about a project beyond the name on its entries.
+type Project struct {
+	Name string
+	// BudgetHours is zero if the project has no budget.
+	BudgetHours  decimal.Decimal
+	BudgetPeriod string
+	// Rate is the hourly rate, zero if none is set.
+	Rate decimal.Decimal
+}
+
+func (project Project) HasBudget() bool {
+	return project.BudgetHours.IsPositive()
+}
+
+// BudgetStatus is how much of the budget of a project is used in the period at a given time.
+type BudgetStatus struct {
+	Project Project
+	At      time.Time
+	// Begin is when the period began, for a total budget when the first entry began.
+	Begin time.Time
+	// End is when the period ends, zero for a total budget.
+	End      time.Time
+	Consumed decimal.Decimal
+}
+
+// TrackedTime is the time tracked on a project within a period, see GetTrackedTime.
+type TrackedTime struct {
+	// First is when the first entry of the project began, zero if it has none.
+	First  time.Time
+	Active time.Duration
+}
+
+// newBudgetPeriod returns the status of project at without anything consumed yet,
+// for a monthly budget Begin and End already are the month of at.
+func newBudgetPeriod(project Project, at time.Time) BudgetStatus {
+	status := BudgetStatus{Project: project, At: at, Consumed: decimal.Zero}
+	if project.BudgetPeriod == BudgetMonth {
+		status.Begin = now.With(at).BeginningOfMonth()
+		status.End = status.Begin.AddDate(0, 1, 0)
+	}
+	return status
+}
+
+// NewBudgetStatus sums up the time of the entries of the project that falls into its budget period at.
+func NewBudgetStatus(project Project, entries []Entry, at time.Time) BudgetStatus {
+	status := newBudgetPeriod(project, at)
+	for _, entry := range entries {
+		if status.End.IsZero() && (status.Begin.IsZero() || entry.Begin.Before(status.Begin)) {
+			status.Begin = entry.Begin
+		}
+	}
+	for _, entry := range entries {
+		from, to := status.Begin, status.End
+		if to.IsZero() {
+			to = entry.end()
+		}
+		status.Consumed = status.Consumed.Add(durationToHours(entry.ActiveDurationBetween(from, to)))
+	}
+	return status
+}
+
+// NewBudgetStatusFromTracked is NewBudgetStatus for the time GetTrackedTime summed up in the budget period at.
+func NewBudgetStatusFromTracked(project Project, tracked TrackedTime, at time.Time) BudgetStatus {
+	status := newBudgetPeriod(project, at)
+	if status.End.IsZero() {
+		status.Begin = tracked.First
+	}
+	status.Consumed = durationToHours(tracked.Active)
+	return status
+}
+
+func durationToHours(duration time.Duration) decimal.Decimal {
+	return decimal.NewFromInt(duration.Nanoseconds()).Div(decimal.NewFromInt(int64(time.Hour)))
+}
+
+func (status BudgetStatus) Remaining() decimal.Decimal {
+	return status.Project.BudgetHours.Sub(status.Consumed)
+}
+
+// Percent is how much of the budget is used in percent, above 100 once it is overrun.
+func (status BudgetStatus) Percent() decimal.Decimal {
+	if !status.Project.HasBudget() {
+		return decimal.Zero
+	}
+	return status.Consumed.Mul(decimal.NewFromInt(100)).Div(status.Project.BudgetHours)
+}
+
+// ProjectedExhaustion is when the budget is used up if work goes on at the rate it went so far,
+// false if nothing was used yet or, for a monthly budget, if it lasts the month.
+func (status BudgetStatus) ProjectedExhaustion() (time.Time, bool) {
+	if !status.Consumed.IsPositive() {
+		return time.Time{}, false
+	}
+	if !status.Remaining().IsPositive() {
+		return status.At, true
+	}
+	// The rate of hours per day, at least a day has passed to not project from a single morning.
+	elapsed := decimal.NewFromFloat(status.At.Sub(status.Begin).Hours() / 24)
+	if elapsed.LessThan(decimal.NewFromInt(1)) {
+		elapsed = decimal.NewFromInt(1)
+	}
+	rate := status.Consumed.Div(elapsed)
+	days := status.Remaining().Div(rate)
+	exhaustion := status.At.Add(time.Duration(days.Mul(decimal.NewFromInt(int64(24 * time.Hour))).IntPart()))
+	if !status.End.IsZero() && !exhaustion.Before(status.End) {
+		return time.Time{}, false
+	}
+	return exhaustion, true
+}
+
+// PeriodName describes the period of the budget, like 'October 2024'.
+func (status BudgetStatus) PeriodName() string {
+	if status.Project.BudgetPeriod == BudgetMonth {
+		return status.Begin.Format("January 2006")
+	}
+	return "in total"
+}
+
+// CrossedThreshold returns the highest threshold of budgetThresholds that lies above before and at or below Percent.
+func (status BudgetStatus) CrossedThreshold(before decimal.Decimal) (int64, bool) {
+	percent := status.Percent()
+	var crossed int64
+	for _, threshold := range budgetThresholds {
+		limit := decimal.NewFromInt(threshold)
+		if before.LessThan(limit) && percent.GreaterThanOrEqual(limit) {
+			crossed = threshold
+		}
+	}
+	return crossed, crossed > 0
+}
+
+func getOutputForBudget(project Project) string {
+	if project.BudgetPeriod == BudgetMonth {
+		return fmt.Sprintf("%sh per month", project.BudgetHours.String())
+	}
+	return fmt.Sprintf("%sh in total", project.BudgetHours.String())
+}
+
+// nullDecimal reads a decimal stored as text, NULL is zero.
+func nullDecimal(value sql.NullString) (decimal.Decimal, error) {
+	if !value.Valid {
+		return decimal.Zero, nil
+	}
+	return decimal.NewFromString(value.String)
+}
+
+func scanProject(row rowScanner) (*Project, error) {
+	var project Project
+	var budgetHours, rate sql.NullString
+	err := row.Scan(&project.Name, &budgetHours, &project.BudgetPeriod, &rate)
+	if err != nil {
+		return nil, err
+	}
+	project.BudgetHours, err = nullDecimal(budgetHours)
+	if err != nil {
+		return nil, err
+	}
+	project.Rate, err = nullDecimal(rate)
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// GetProject returns the project with name, sql.ErrNoRows if nothing was set for it yet.
+func (db *Database) GetProject(name string) (*Project, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	return scanProject(db.DB.QueryRowContext(ctx, `SELECT name, budget_hours, budget_period, rate FROM projects WHERE key = ?;`, GetIdFromName(name)))
+}
+
+func (db *Database) GetProjects() ([]Project, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	rows, err := db.DB.QueryContext(ctx, `SELECT name, budget_hours, budget_period, rate FROM projects ORDER BY name;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var projects []Project
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, *project)
+	}
+	return projects, rows.Err()
+}
+
+func (db *Database) SetProjectBudget(project Project) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err := db.DB.ExecContext(ctx, `INSERT INTO projects(key, name, budget_hours, budget_period) VALUES(?, ?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET name = excluded.name, budget_hours = excluded.budget_hours, budget_period = excluded.budget_period;`,
+		GetIdFromName(project.Name), project.Name, project.BudgetHours.String(), project.BudgetPeriod)
+	return err
+}
+
+// RemoveProjectBudget removes the budget of the project, sql.ErrNoRows if it has none.
+func (db *Database) RemoveProjectBudget(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	result, err := db.DB.ExecContext(ctx, `UPDATE projects SET budget_hours = NULL WHERE key = ? AND budget_hours IS NOT NULL;`, GetIdFromName(name))
+	if err != nil {
+		return err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetTrackedTime sums up the time tracked on project between from and to in SQL, pauses not counted,
+// like ActiveDurationBetween does for every entry. A zero from or to leaves that side open.
+func (db *Database) GetTrackedTime(project string, from time.Time, to time.Time) (TrackedTime, error) {
+	var tracked TrackedTime
+	fromUnix, toUnix := int64(math.MinInt64), int64(math.MaxInt64)
+	if !from.IsZero() {
+		fromUnix = from.Unix()
+	}
+	if !to.IsZero() {
+		toUnix = to.Unix()
+	}
+	query := `WITH spans AS (
+			SELECT id, MAX(start, ?) AS span_start, MIN(CASE WHEN finish IS NULL OR finish < start THEN ? ELSE finish END, ?) AS span_finish
+			FROM entries
+			WHERE project_key = ? AND start < ?)
+		SELECT (SELECT MIN(start) FROM entries WHERE project_key = ?),
+			COALESCE(SUM(MAX(0, span_finish - span_start - COALESCE((
+				SELECT SUM(MAX(0, MIN(COALESCE(pauses.finish, span_finish), span_finish) - MAX(pauses.start, span_start)))
+				FROM pauses WHERE pauses.entry_id = spans.id), 0))), 0)
+		FROM spans
+		WHERE span_finish > span_start;`
+	key := GetIdFromName(project)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	var first sql.NullInt64
+	var seconds int64
+	err := db.DB.QueryRowContext(ctx, query, fromUnix, time.Now().Unix(), toUnix, key, toUnix, key).Scan(&first, &seconds)
+	if err != nil {
+		return tracked, err
+	}
+	if first.Valid {
+		tracked.First = time.Unix(first.Int64, 0)
+	}
+	tracked.Active = time.Duration(seconds) * time.Second
+	return tracked, nil
+}
@@ -0,0 +1,200 @@
+package z
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var budgetHours string
+var budgetPeriod string
+
+var budgetCmd = &cobra.Command{
+	Use:   "budget",
+	Short: "Show the hour budgets of projects",
+	Long: `Show how much of the hour budget of each project is used, how much remains
+and when it will be used up if work goes on at the rate it went so far.
+
+A budget is either a total of hours or hours per month, set via 'zeit budget set'.
+'zeit track' and 'zeit finish' warn once 80% and 100% of a budget are used.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var projects []Project
+		if project != "" {
+			budgetProject, err := database.GetProject(project)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				fmt.Printf("%s %+v\n", CharError, err)
+				os.Exit(1)
+			}
+			if budgetProject == nil || !budgetProject.HasBudget() {
+				fmt.Printf("%s %s has no budget, set one with 'zeit budget set'\n", CharInfo, project)
+				return
+			}
+			projects = append(projects, *budgetProject)
+		} else {
+			allProjects, err := database.GetProjects()
+			if err != nil {
+				fmt.Printf("%s %+v\n", CharError, err)
+				os.Exit(1)
+			}
+			for _, budgetProject := range allProjects {
+				if budgetProject.HasBudget() {
+					projects = append(projects, budgetProject)
+				}
+			}
+		}
+		if len(projects) == 0 {
+			fmt.Printf("%s no project has a budget yet, set one with 'zeit budget set'\n", CharInfo)
+			return
+		}
+
+		for _, budgetProject := range projects {
+			status := getBudgetStatus(budgetProject)
+			fmt.Printf("%s %s (%s)\n", CharMore, color.FgLightWhite.Render(budgetProject.Name), getOutputForBudget(budgetProject))
+			fmt.Print(getOutputForBudgetStatus(status))
+		}
+	},
+}
+
+var budgetSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set the budget of a project",
+	Run: func(cmd *cobra.Command, args []string) {
+		if project == "" {
+			fmt.Printf("%s Please give the project via --project\n", CharError)
+			os.Exit(1)
+		}
+		hours, err := decimal.NewFromString(budgetHours)
+		if err != nil || !hours.IsPositive() {
+			fmt.Printf("%s --hours has to be a number of hours above 0, e.g. 40 or 7.5\n", CharError)
+			os.Exit(1)
+		}
+		if budgetPeriod != BudgetTotal && budgetPeriod != BudgetMonth {
+			fmt.Printf("%s --period has to be either %s or %s.\n", CharError, BudgetTotal, BudgetMonth)
+			os.Exit(1)
+		}
+		budgetProject := Project{Name: project, BudgetHours: hours, BudgetPeriod: budgetPeriod}
+		err = database.SetProjectBudget(budgetProject)
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s %s has a budget of %s\n", CharInfo, color.FgLightWhite.Render(project), getOutputForBudget(budgetProject))
+	},
+}
+
+var budgetRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove the budget of a project",
+	Run: func(cmd *cobra.Command, args []string) {
+		if project == "" {
+			fmt.Printf("%s Please give the project via --project\n", CharError)
+			os.Exit(1)
+		}
+		err := database.RemoveProjectBudget(project)
+		if errors.Is(err, sql.ErrNoRows) {
+			fmt.Printf("%s %s has no budget.\n", CharError, project)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s removed the budget of %s\n", CharErase, color.FgLightWhite.Render(project))
+	},
+}
+
+func getBudgetStatus(budgetProject Project) BudgetStatus {
+	at := time.Now()
+	period := newBudgetPeriod(budgetProject, at)
+	tracked, err := database.GetTrackedTime(budgetProject.Name, period.Begin, period.End)
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+	return NewBudgetStatusFromTracked(budgetProject, tracked, at)
+}
+
+func getOutputForBudgetStatus(status BudgetStatus) string {
+	remaining := status.Remaining()
+	remainingColor := color.FgLightWhite
+	if !remaining.IsPositive() {
+		remainingColor = color.FgLightRed
+	}
+	output := fmt.Sprintf("   %sh used %s (%s%%), %sh remaining\n",
+		color.FgLightWhite.Render(fmtHours(status.Consumed)),
+		status.PeriodName(),
+		status.Percent().StringFixed(0),
+		remainingColor.Render(fmtHours(remaining)))
+	exhaustion, ok := status.ProjectedExhaustion()
+	switch {
+	case !remaining.IsPositive():
+		output += fmt.Sprintf("   %s\n", color.FgLightRed.Render("the budget is used up"))
+	case ok:
+		output += fmt.Sprintf("   used up by %s at the current rate\n", color.FgLightWhite.Render(exhaustion.Format("2006-01-02")))
+	case status.Project.BudgetPeriod == BudgetMonth && status.Consumed.IsPositive():
+		output += "   lasts the month at the current rate\n"
+	}
+	return output
+}
+
+// warnBudget warns when entry made the budget of its project cross 80% or 100%.
+// A running entry is warned about as long as its project is above a threshold, to not start working on it unaware.
+func warnBudget(entry Entry) {
+	budgetProject, err := database.GetProject(entry.Project)
+	if errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		fmt.Printf("%s could not check the budget of %s. Error: %s\n", CharError, entry.Project, err.Error())
+		return
+	}
+	if !budgetProject.HasBudget() {
+		return
+	}
+	status := getBudgetStatus(*budgetProject)
+	before := decimal.Zero
+	if !entry.Running {
+		single := NewBudgetStatus(*budgetProject, []Entry{entry}, status.At)
+		before = status.Percent().Sub(single.Percent())
+	}
+	threshold, ok := status.CrossedThreshold(before)
+	if !ok {
+		return
+	}
+	if threshold >= 100 {
+		fmt.Printf("%s %s is over its budget of %s: %sh used %s.\n", CharError,
+			color.FgLightWhite.Render(entry.Project), getOutputForBudget(*budgetProject), fmtHours(status.Consumed), status.PeriodName())
+		return
+	}
+	fmt.Printf("%s %s has used %s%% of its budget of %s, %sh remaining.\n", CharError,
+		color.FgLightWhite.Render(entry.Project), status.Percent().StringFixed(0), getOutputForBudget(*budgetProject), fmtHours(status.Remaining()))
+}
+
+func init() {
+	rootCmd.AddCommand(budgetCmd)
+	budgetCmd.AddCommand(budgetSetCmd)
+	budgetCmd.AddCommand(budgetRemoveCmd)
+	budgetCmd.Flags().StringVarP(&project, "project", "p", "", "Only show the budget of this project")
+	budgetCmd.Flags().BoolVar(&fractional, "decimal", true, "Show fractional hours in decimal format instead of minutes")
+	budgetSetCmd.Flags().StringVarP(&project, "project", "p", "", "Project to set the budget for")
+	budgetSetCmd.Flags().StringVar(&budgetHours, "hours", "", "Hours the budget amounts to")
+	budgetSetCmd.Flags().StringVar(&budgetPeriod, "period", BudgetTotal, "Period the budget is for: total or month")
+	budgetRemoveCmd.Flags().StringVarP(&project, "project", "p", "", "Project to remove the budget of")
+
+	registerProjectTaskCompletion(budgetCmd, false)
+	registerProjectTaskCompletion(budgetSetCmd, false)
+	registerProjectTaskCompletion(budgetRemoveCmd, false)
+
+	var err error
+	database, err = InitDB()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+}
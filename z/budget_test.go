@@ -0,0 +1,55 @@
+package z
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestGetTrackedTimeMatchesEntries(t *testing.T) {
+	db := newTestDatabase(t)
+	at := time.Date(2024, 9, 10, 12, 0, 0, 0, time.Local)
+	on := func(month time.Month, day int, hour int) time.Time {
+		return time.Date(2024, month, day, hour, 0, 0, 0, time.Local)
+	}
+	entries := []Entry{
+		{Project: "Acme", Task: "Before", Begin: on(time.August, 5, 9), Finish: on(time.August, 5, 12)},
+		// Runs over the turn of the month with a pause on either side of it.
+		{Project: "Acme", Task: "Across", Begin: on(time.August, 31, 22), Finish: on(time.September, 1, 3), Pauses: []Pause{
+			{Begin: on(time.August, 31, 23), Finish: on(time.September, 1, 1)},
+		}},
+		{Project: "Acme", Task: "Within", Begin: on(time.September, 2, 9), Finish: on(time.September, 2, 17), Pauses: []Pause{
+			{Begin: on(time.September, 2, 12), Finish: on(time.September, 2, 13)},
+		}},
+		{Project: "Other", Task: "Within", Begin: on(time.September, 3, 9), Finish: on(time.September, 3, 17)},
+	}
+	ctx := context.Background()
+	for i := range entries {
+		entries[i].SetDateFromBegining()
+		entries[i].Hours = entries[i].GetDuration()
+		err := insertEntry(ctx, db.DB, &entries[i], false)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// September has 2 hours of 'Across' and 7 of 'Within', in total 'Before' and 3 hours of 'Across' add to that.
+	for period, hours := range map[string]int64{BudgetMonth: 9, BudgetTotal: 13} {
+		project := Project{Name: "acme", BudgetHours: decimal.NewFromInt(40), BudgetPeriod: period}
+		want := NewBudgetStatus(project, entries[:3], at)
+		if !want.Consumed.Equal(decimal.NewFromInt(hours)) {
+			t.Fatalf("%s budget of the entries consumed %sh, want %dh", period, want.Consumed, hours)
+		}
+		status := newBudgetPeriod(project, at)
+		tracked, err := db.GetTrackedTime(project.Name, status.Begin, status.End)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := NewBudgetStatusFromTracked(project, tracked, at)
+		if !got.Consumed.Equal(want.Consumed) || !got.Begin.Equal(want.Begin) {
+			t.Errorf("%s budget consumed %sh since %s, want %sh since %s", period, got.Consumed, got.Begin, want.Consumed, want.Begin)
+		}
+	}
+}
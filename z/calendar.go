@@ -36,7 +36,8 @@ type Calendar struct {
 	TotalHours   decimal.Decimal
 }
 
-func NewCalendar(entries []Entry) (Calendar, error) {
+// NewCalendar sums up the hours of the entries, an entry listed in shares only counts with that part of its hours.
+func NewCalendar(entries []Entry, shares map[int64]decimal.Decimal) (Calendar, error) {
 	cal := Calendar{}
 
 	cal.Distribution = make(map[string]Statistic)
@@ -73,20 +74,22 @@ func NewCalendar(entries []Entry) (Calendar, error) {
 		if endOfBeginDay.Before(entryFinish) {
 			startOfFinishDay := now.With(entryFinish).BeginningOfDay()
 
-			sameDayDuration := endOfBeginDay.Sub(entry.Begin)
-			sameDay := sameDayDuration.Hours()
+			sameDay := entry.ActiveDurationBetween(entry.Begin, endOfBeginDay).Hours()
 			sameDayHours = decimal.NewFromFloat(sameDay)
 
-			nextDayDuration := entryFinish.Sub(startOfFinishDay)
-			nextDay := nextDayDuration.Hours()
+			nextDay := entry.ActiveDurationBetween(startOfFinishDay, entryFinish).Hours()
 			nextDayHours = decimal.NewFromFloat(nextDay)
 
 		} else {
-			sameDayDuration := entryFinish.Sub(entry.Begin)
-			sameDay := sameDayDuration.Hours()
+			sameDay := entry.ActiveDuration().Hours()
 			sameDayHours = decimal.NewFromFloat(sameDay)
 		}
 
+		if share, ok := shares[entry.ID]; ok {
+			sameDayHours = sameDayHours.Mul(share)
+			nextDayHours = nextDayHours.Mul(share)
+		}
+
 		if sameDayHours.GreaterThan(decimal.NewFromInt(0)) {
 			month, weeknumber := GetISOWeekInMonth(entry.Begin)
 			month0 := month - 1
@@ -0,0 +1,105 @@
+package z
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// completionEntries is how many of the latest entries with a matching id are offered when completing an id.
+const completionEntries = 100
+
+// registerProjectTaskCompletion completes --project and --task of cmd from the tracked entries,
+// the tasks are those of the project given already. withAliases offers '@alias' for --project as well.
+func registerProjectTaskCompletion(cmd *cobra.Command, withAliases bool) {
+	cmd.RegisterFlagCompletionFunc("project", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		projects, err := database.GetUniqueProjects()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		if withAliases {
+			projects = append(projects, completeAliases()...)
+		}
+		return projects, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.RegisterFlagCompletionFunc("task", completeTasks)
+}
+
+func completeTasks(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	taskProject := project
+	if IsAlias(taskProject) {
+		alias, err := database.GetAlias(aliasName(taskProject))
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		taskProject = alias.Project
+	}
+	tasks, err := database.GetUniqueTasks(taskProject)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return tasks, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeAliases returns all aliases as '@name' with what they stand for as description.
+func completeAliases() []string {
+	aliases, err := database.GetAliases()
+	if err != nil {
+		return nil
+	}
+	var completions []string
+	for _, alias := range aliases {
+		completions = append(completions, fmt.Sprintf("@%s\t%s %s", alias.Name, alias.Project, alias.Task))
+	}
+	return completions
+}
+
+// completeAliasArg completes the optional '@alias' argument of track and switch.
+func completeAliasArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeAliases(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeEntryID completes the id argument of entry and erase with the latest entries whose id begins with toComplete.
+func completeEntryID(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if strings.Trim(toComplete, "0123456789") != "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	// The prefix is matched before the limit, so older ids can be completed as well.
+	entries, err := database.QueryEntries(EntryQuery{IDPrefix: toComplete, Order: OrderByBeginDesc, Limit: completionEntries})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return entryIDCompletions(entries, toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeRunningEntryID completes the id argument of the commands working on a running entry.
+func completeRunningEntryID(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	entries, err := database.GetRunningEntries()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return entryIDCompletions(entries, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func entryIDCompletions(entries []Entry, toComplete string) []string {
+	var completions []string
+	for _, entry := range entries {
+		id := fmt.Sprint(entry.ID)
+		if !strings.HasPrefix(id, toComplete) {
+			continue
+		}
+		entry.Hours = entry.GetDuration().Round(2)
+		completions = append(completions, fmt.Sprintf("%s\t%s", id, strings.TrimSpace(entry.GetOutputStrShort())))
+	}
+	return completions
+}
@@ -0,0 +1,38 @@
+package z
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompleteEntryIDBeyondTheLatest(t *testing.T) {
+	db := newTestDatabase(t)
+	seedEntries(t, db, time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC), completionEntries+50)
+	previous := database
+	database = db
+	defer func() { database = previous }()
+
+	ids := func(toComplete string) []string {
+		completions, _ := completeEntryID(nil, nil, toComplete)
+		var ids []string
+		for _, completion := range completions {
+			id, _, _ := strings.Cut(completion, "\t")
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	latest := ids("")
+	if len(latest) != completionEntries || latest[0] != "150" {
+		t.Fatalf("completing nothing offers %d ids starting with %v, want the latest %d", len(latest), latest[:1], completionEntries)
+	}
+	// Entry 7 is older than the latest 100, yet it is offered along with 70 to 79.
+	seven := ids("7")
+	if len(seven) != 11 || seven[len(seven)-1] != "7" {
+		t.Errorf("completing '7' offers %v, want 79 to 70 and 7", seven)
+	}
+	if none := ids("x"); len(none) != 0 {
+		t.Errorf("completing 'x' offers %v, want nothing", none)
+	}
+}
@@ -0,0 +1,79 @@
+package z
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// How 'zeit stats' counts time that several timers tracked at once.
+const (
+	ConcurrentFull  = "full"
+	ConcurrentSplit = "split"
+)
+
+// ConcurrentShares returns the part of its tracked time every entry keeps when time that
+// several entries tracked at once is split evenly between them. Entries that never ran
+// alongside another one are missing from the map, they keep all of their time.
+func ConcurrentShares(entries []Entry) map[int64]decimal.Decimal {
+	// Between two neighbouring boundaries every entry is either tracking or not.
+	var boundaries []time.Time
+	for _, entry := range entries {
+		boundaries = append(boundaries, entry.Begin, entry.end())
+		for _, pause := range entry.Pauses {
+			boundaries = append(boundaries, pause.Begin)
+			if !pause.Finish.IsZero() {
+				boundaries = append(boundaries, pause.Finish)
+			}
+		}
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i].Before(boundaries[j]) })
+
+	byBegin := make([]Entry, len(entries))
+	copy(byBegin, entries)
+	sort.Slice(byBegin, func(i, j int) bool { return byBegin[i].Begin.Before(byBegin[j].Begin) })
+
+	split := make(map[int64]time.Duration)
+	concurrent := make(map[int64]bool)
+	var open []Entry
+	next := 0
+	for i := 0; i+1 < len(boundaries); i++ {
+		from, to := boundaries[i], boundaries[i+1]
+		if !from.Before(to) {
+			continue
+		}
+		for next < len(byBegin) && !byBegin[next].Begin.After(from) {
+			open = append(open, byBegin[next])
+			next++
+		}
+		var tracking []int64
+		stillOpen := open[:0]
+		for _, entry := range open {
+			if !entry.end().After(from) {
+				continue
+			}
+			stillOpen = append(stillOpen, entry)
+			if entry.ActiveDurationBetween(from, to) > 0 {
+				tracking = append(tracking, entry.ID)
+			}
+		}
+		open = stillOpen
+		for _, id := range tracking {
+			split[id] += to.Sub(from) / time.Duration(len(tracking))
+			if len(tracking) > 1 {
+				concurrent[id] = true
+			}
+		}
+	}
+
+	shares := make(map[int64]decimal.Decimal)
+	for _, entry := range entries {
+		active := entry.ActiveDuration()
+		if !concurrent[entry.ID] || active <= 0 {
+			continue
+		}
+		shares[entry.ID] = decimal.NewFromInt(int64(split[entry.ID])).Div(decimal.NewFromInt(int64(active)))
+	}
+	return shares
+}
@@ -0,0 +1,165 @@
+package z
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Settings that are not worth a flag on every command are read from the environment, like 'ZEIT_DB'.
+
+const (
+	defaultMaxRunning = 12 * time.Hour
+	defaultWorkdayEnd = "18:00"
+	defaultCurrency   = "EUR"
+	// defaultBranchPattern turns 'feature/PROJ-123-login' into the ticket 'PROJ-123' and the title 'login'.
+	defaultBranchPattern = `^(?:[^/]+/)?([A-Za-z][A-Za-z0-9]*-[0-9]+)[-_]?(.*)$`
+)
+
+var maxRunning time.Duration
+
+// maxRunningDuration is how long an entry may run before it counts as a forgotten timer.
+// '--max-running' wins over 'ZEIT_MAX_RUNNING' (e.g. '10h'), which wins over the default of 12h.
+func maxRunningDuration() time.Duration {
+	if maxRunning > 0 {
+		return maxRunning
+	}
+	value, ok := os.LookupEnv("ZEIT_MAX_RUNNING")
+	if !ok || value == "" {
+		return defaultMaxRunning
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		fmt.Printf("%s could not parse ZEIT_MAX_RUNNING '%s', using %s\n", CharError, value, defaultMaxRunning)
+		return defaultMaxRunning
+	}
+	return duration
+}
+
+// workdayEnd is the end of the working day the given time falls on, 'ZEIT_WORKDAY_END' (e.g. '17:30') or 18:00.
+func workdayEnd(day time.Time) time.Time {
+	value, ok := os.LookupEnv("ZEIT_WORKDAY_END")
+	if !ok || value == "" {
+		value = defaultWorkdayEnd
+	}
+	end, err := time.Parse("15:04", value)
+	if err != nil {
+		fmt.Printf("%s could not parse ZEIT_WORKDAY_END '%s', using %s\n", CharError, value, defaultWorkdayEnd)
+		end, _ = time.Parse("15:04", defaultWorkdayEnd)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), end.Hour(), end.Minute(), 0, 0, day.Location())
+}
+
+// What happens when an entry is saved over the time of another one, set via 'ZEIT_OVERLAP'.
+const (
+	OverlapWarn   = "warn"
+	OverlapReject = "reject"
+	OverlapIgnore = "ignore"
+)
+
+var overlapPolicy string
+
+// overlapMode is '--overlap' if given, else 'ZEIT_OVERLAP', else warn.
+func overlapMode() string {
+	value := overlapPolicy
+	if value == "" {
+		value = os.Getenv("ZEIT_OVERLAP")
+	}
+	switch value {
+	case "":
+		return OverlapWarn
+	case OverlapWarn, OverlapReject, OverlapIgnore:
+		return value
+	}
+	fmt.Printf("%s unknown overlap mode '%s', using %s\n", CharError, value, OverlapWarn)
+	return OverlapWarn
+}
+
+var parallel bool
+
+// parallelEnabled reports if a timer may be started while others are running,
+// with '--parallel' or 'ZEIT_PARALLEL=true'. By default only a single timer runs at a time.
+func parallelEnabled() bool {
+	if parallel {
+		return true
+	}
+	value, ok := os.LookupEnv("ZEIT_PARALLEL")
+	if !ok || value == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		fmt.Printf("%s could not parse ZEIT_PARALLEL '%s', parallel timers stay disabled\n", CharError, value)
+		return false
+	}
+	return enabled
+}
+
+var branchPattern string
+
+// branchRegexp is how the git hooks derive a task from a branch name,
+// '--branch-pattern' wins over 'ZEIT_BRANCH_PATTERN', which wins over defaultBranchPattern.
+func branchRegexp() (*regexp.Regexp, error) {
+	value := branchPattern
+	if value == "" {
+		value = os.Getenv("ZEIT_BRANCH_PATTERN")
+	}
+	if value == "" {
+		value = defaultBranchPattern
+	}
+	return regexp.Compile(value)
+}
+
+// Settings of invoices, each one is the flag of 'zeit invoice' if given, else its environment variable.
+var invoiceCurrency string
+var invoiceTaxRate string
+var invoiceSender string
+var invoiceRecipient string
+
+func flagOrEnv(flag string, env string) string {
+	if flag != "" {
+		return flag
+	}
+	return os.Getenv(env)
+}
+
+// currency is '--currency', else 'ZEIT_CURRENCY', else EUR.
+func currency() string {
+	value := flagOrEnv(invoiceCurrency, "ZEIT_CURRENCY")
+	if value == "" {
+		return defaultCurrency
+	}
+	return value
+}
+
+// taxRate is '--tax' or 'ZEIT_TAX_RATE' in percent, e.g. '19', no tax by default.
+func taxRate() (decimal.Decimal, error) {
+	value := flagOrEnv(invoiceTaxRate, "ZEIT_TAX_RATE")
+	if value == "" {
+		return decimal.Zero, nil
+	}
+	rate, err := decimal.NewFromString(value)
+	if err != nil || rate.IsNegative() {
+		return decimal.Zero, fmt.Errorf("the tax rate has to be a percentage like 19 or 7.7, not '%s'", value)
+	}
+	return rate, nil
+}
+
+// sender and recipient are the address blocks of an invoice, '\n' separates their lines.
+func sender() string {
+	return strings.ReplaceAll(flagOrEnv(invoiceSender, "ZEIT_INVOICE_SENDER"), "\\n", "\n")
+}
+
+func recipient() string {
+	return strings.ReplaceAll(flagOrEnv(invoiceRecipient, "ZEIT_INVOICE_RECIPIENT"), "\\n", "\n")
+}
+
+// invoiceNumberPrefix is put in front of the invoice numbers, 'ZEIT_INVOICE_PREFIX' like 'INV-', none by default.
+func invoiceNumberPrefix() string {
+	return os.Getenv("ZEIT_INVOICE_PREFIX")
+}
@@ -0,0 +1,124 @@
+package z
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+var continueWithNotes bool
+var continuePick bool
+var continueLast int
+
+var continueCmd = &cobra.Command{
+	Use:   "continue ([flags]) [id]",
+	Short: "Continue a previous activity",
+	Long: `Start tracking a new activity with the project and task of a previous one.
+
+Without an id the most recently finished activity is continued,
+with --pick one of the last tracked project/task pairs can be chosen.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		exitIfEntryRunning()
+
+		var previous *Entry
+		var err error
+		switch {
+		case len(args) == 1:
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				fmt.Printf("%s %s", CharError, "Please provide a valid number")
+				os.Exit(1)
+			}
+			previous, err = database.GetEntry(int64(id))
+			if err != nil {
+				fmt.Printf("%s %+v\n", CharError, err)
+				os.Exit(1)
+			}
+		case continuePick:
+			previous = pickProjectTask()
+		default:
+			lastEntries, err := database.QueryEntries(EntryQuery{Finished: true, Order: OrderByFinishDesc, Limit: 1})
+			if err != nil {
+				fmt.Printf("%s %+v\n", CharError, err)
+				os.Exit(1)
+			}
+			if len(lastEntries) == 0 {
+				fmt.Printf("%s there is no finished task to continue yet.\n", CharError)
+				os.Exit(1)
+			}
+			previous = &lastEntries[0]
+		}
+
+		newEntry := NewEntry(previous.Project, previous.Task)
+		if continueWithNotes {
+			newEntry.Notes = previous.Notes
+		}
+		if notes != "" {
+			newEntry.Notes = notes
+		}
+		_, err = newEntry.SetBeginFromString(begin)
+		if err != nil {
+			fmt.Printf("%s could not parse --begin '%s'. Error: %s\n", CharError, begin, err.Error())
+			os.Exit(1)
+		}
+		newEntry.SetDateFromBegining()
+
+		startEntry(&newEntry)
+		fmt.Println(newEntry.GetStartTrackingStr())
+	},
+}
+
+// pickProjectTask lets the user choose one of the recently tracked project/task pairs and returns its latest activity.
+func pickProjectTask() *Entry {
+	projectTasks, err := database.GetRecentProjectTasks(continueLast)
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+	if len(projectTasks) == 0 {
+		fmt.Printf("%s there is no task to continue yet.\n", CharError)
+		os.Exit(1)
+	}
+	for i, projectTask := range projectTasks {
+		fmt.Printf("%s %s on %s\n",
+			color.FgGray.Render(fmt.Sprintf("%2d", i+1)),
+			color.FgLightWhite.Render(projectTask.Task),
+			color.FgLightWhite.Render(projectTask.Project))
+	}
+	choice, err := strconv.Atoi(ask("Which one do you want to continue?"))
+	if err != nil || choice < 1 || choice > len(projectTasks) {
+		fmt.Printf("%s Please choose a number between 1 and %d\n", CharError, len(projectTasks))
+		os.Exit(1)
+	}
+	picked := projectTasks[choice-1]
+	// The latest activity of the pair has the notes --with-notes copies.
+	latest, err := database.QueryEntries(EntryQuery{Project: picked.Project, Task: picked.Task, Order: OrderByBeginDesc, Limit: 1})
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+	if len(latest) == 0 {
+		return &Entry{Project: picked.Project, Task: picked.Task}
+	}
+	return &latest[0]
+}
+
+func init() {
+	rootCmd.AddCommand(continueCmd)
+	continueCmd.Flags().StringVarP(&begin, "begin", "b", "", "Time the activity should begin at\n\nEither in the formats 16:00 / 4:00PM \nor relative to the current time, \ne.g. -0:15 (now minus 15 minutes), +1.50 (now plus 1:30h).")
+	continueCmd.Flags().StringVarP(&notes, "notes", "n", "", "Activity notes")
+	continueCmd.Flags().BoolVar(&continueWithNotes, "with-notes", false, "Copy the notes of the previous activity")
+	continueCmd.Flags().BoolVar(&continuePick, "pick", false, "Pick one of the last tracked project/task pairs")
+	continueCmd.Flags().IntVar(&continueLast, "last", 10, "Number of project/task pairs to choose from with --pick")
+
+	var err error
+	database, err = InitDB()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+}
@@ -3,56 +3,336 @@ package z
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"strings"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 	"github.com/shopspring/decimal"
 )
 
+// Store is everything the commands need from the storage layer.
+// Database is the SQLite implementation of it.
+type Store interface {
+	EntryStore
+	PauseStore
+	QueryStore
+	AliasStore
+	RecurringStore
+	ProjectStore
+	BillingStore
+	SchemaStore
+}
+
+// EntryStore adds, changes and removes single entries.
+type EntryStore interface {
+	AddEntry(entry *Entry, running bool) ([]Entry, error)
+	GetEntry(id int64) (*Entry, error)
+	UpdateEntry(entry Entry) ([]Entry, error)
+	AddFinishToEntry(entry Entry) error
+	SwitchEntry(running Entry, next *Entry) ([]Entry, error)
+	DeleteEntry(id int64) error
+	GetRunningEntry() (*Entry, error)
+	GetRunningEntries() ([]Entry, error)
+	EnsureSingleRunningIndex() error
+}
+
+// PauseStore keeps the pauses of running entries.
+type PauseStore interface {
+	PauseEntry(entry Entry, at time.Time) error
+	ResumeEntry(entry Entry, at time.Time) error
+}
+
+// QueryStore reads entries and what is summed up of them for lists, reports and completion.
+type QueryStore interface {
+	GetAllEntries() ([]Entry, error)
+	QueryEntries(query EntryQuery) ([]Entry, error)
+	GetEntriesViaProject(project string) ([]Entry, error)
+	GetEntriesBeforeDate(date time.Time) ([]Entry, error)
+	GetEntriesAfterDate(date time.Time) ([]Entry, error)
+	GetEntriesPerDay(project string) ([]EntriesGroupedByDay, error)
+	GetPomodorosPerDay(query EntryQuery) ([]PomodorosOfDay, error)
+	GetTrackedTime(project string, from time.Time, to time.Time) (TrackedTime, error)
+	GetUniqueProjects() ([]string, error)
+	GetUniqueTasks(project string) ([]string, error)
+	GetRecentProjectTasks(limit int) ([]ProjectTask, error)
+	GetOverlaps() ([]Overlap, error)
+}
+
+// AliasStore keeps the '@alias' shortcuts for project/task pairs.
+type AliasStore interface {
+	AddAlias(alias Alias, replace bool) error
+	GetAlias(name string) (*Alias, error)
+	GetAliases() ([]Alias, error)
+	DeleteAlias(name string) error
+}
+
+// RecurringStore keeps the recurring activities and the holidays they skip.
+type RecurringStore interface {
+	AddRecurring(recurring *Recurring) error
+	GetRecurring() ([]Recurring, error)
+	DeleteRecurring(id int64) error
+	ApplyRecurring(until time.Time) ([]Entry, []Entry, error)
+	AddHoliday(holiday Holiday) error
+	GetHolidays() ([]Holiday, error)
+	DeleteHoliday(day time.Time) error
+}
+
+// ProjectStore keeps what is known about projects: their repositories, the rules detecting them and their budgets.
+type ProjectStore interface {
+	AddProjectRepo(repo ProjectRepo) error
+	GetProjectRepos(project string) ([]ProjectRepo, error)
+	DeleteProjectRepo(repo ProjectRepo) error
+	AddProjectRule(rule *ProjectRule) error
+	GetProjectRules() ([]ProjectRule, error)
+	DeleteProjectRule(id int64) error
+	GetProject(name string) (*Project, error)
+	GetProjects() ([]Project, error)
+	SetProjectBudget(project Project) error
+	RemoveProjectBudget(name string) error
+}
+
+// BillingStore keeps the hourly rates and the invoices written.
+type BillingStore interface {
+	SetProjectRate(name string, rate decimal.Decimal) error
+	SetTaskRate(rate TaskRate) error
+	GetTaskRates(project string) ([]TaskRate, error)
+	GetRates(project string) (Rates, error)
+	RemoveProjectRate(name string) error
+	RemoveTaskRate(project string, task string) error
+	AddInvoice(invoice *Invoice) error
+	GetInvoices() ([]InvoiceRecord, error)
+	VoidInvoice(number string) (int64, error)
+}
+
+// SchemaStore migrates the schema of the storage.
+type SchemaStore interface {
+	SchemaVersion() (int, error)
+	PendingMigrations() ([]Migration, error)
+	Migrate() ([]Migration, error)
+}
+
+var defaultDBNotice sync.Once
+
 type Database struct {
-	DB *sql.DB
+	DB   *sql.DB
+	Path string
 }
 
+var _ Store = (*Database)(nil)
+
 // Decision: We do not care about the UUID and would rather use incremental ID to make also selecting easier.
 // Also I would like to remove the 'user' from the equation as this is suppose to be a 'one user' CLI
 func InitDB() (*Database, error) {
 	// Will make '.config/zeit.db' the default
 	dbLocation, ok := os.LookupEnv("ZEIT_DB")
 	if !ok || dbLocation == "" {
-		fmt.Println("Did not find 'ZEIT_DB' env. variable specified. Will use `$HOME/.config/zeit.db` as default")
-		dbLocation = "$HOME/.config/zeit.db"
+		// Every command opens the database, so this would be printed once per command otherwise.
+		// It goes to stderr to keep it out of shell completions and exports.
+		defaultDBNotice.Do(func() {
+			fmt.Fprintln(os.Stderr, "Did not find 'ZEIT_DB' env. variable specified. Will use `$HOME/.config/zeit.db` as default")
+		})
+		dbLocation = os.ExpandEnv("$HOME/.config/zeit.db")
 	}
-	db, err := sql.Open("sqlite3", dbLocation)
+	return openDatabase(dbLocation)
+}
+
+// openDatabase opens the database at dbLocation, pending migrations are applied by prepareDatabase.
+func openDatabase(dbLocation string) (*Database, error) {
+	// IMMEDIATE transactions take the write lock right away, so 'check then write' can not interleave between processes.
+	dsnSeparator := "?"
+	if strings.Contains(dbLocation, "?") {
+		dsnSeparator = "&"
+	}
+	db, err := sql.Open("sqlite3", dbLocation+dsnSeparator+"_txlock=immediate&_busy_timeout=5000")
 	if err != nil {
 		fmt.Printf("Encountered error opening the db, Error: %s", err.Error())
 		return nil, err
 	}
-	err = createDefaultTables(db)
+	return &Database{DB: db, Path: dbLocation}, nil
+}
+
+// entryColumns lists the columns of the entries table in the order scanEntry expects them.
+const entryColumns = `id, date, start, start_offset, finish, finish_offset, seconds, project, task, notes, pomodoro, parallel, running, billing_state, invoice_number`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEntry(row rowScanner) (*Entry, error) {
+	var entryRow EntryRow
+	err := row.Scan(
+		&entryRow.ID,
+		&entryRow.Date,
+		&entryRow.Start,
+		&entryRow.StartOffset,
+		&entryRow.Finish,
+		&entryRow.FinishOffset,
+		&entryRow.Seconds,
+		&entryRow.Project,
+		&entryRow.Task,
+		&entryRow.Notes,
+		&entryRow.Pomodoro,
+		&entryRow.Parallel,
+		&entryRow.Running,
+		&entryRow.BillingState,
+		&entryRow.InvoiceNumber)
+	if err != nil {
+		return nil, err
+	}
+	return entryRow.ConvertToEntry(), nil
+}
+
+func (db *Database) queryEntries(query string, args ...any) ([]Entry, error) {
+	// Listing years of history takes longer than the single row statements.
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []Entry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return entries, nil
+	}
+	err = db.loadPauses(ctx, entries, query, args...)
 	if err != nil {
 		return nil, err
 	}
-	return &Database{DB: db}, nil
+	return entries, nil
 }
 
-func (db *Database) AddEntry(entry *Entry, running bool) error {
+// loadPauses attaches the pauses to entries, which have to be the result of entryQuery.
+// The query is reused as a sub-select, so this is one more statement no matter how many entries there are.
+func (db *Database) loadPauses(ctx context.Context, entries []Entry, entryQuery string, args ...any) error {
+	query := `SELECT entry_id, start, start_offset, finish, finish_offset FROM pauses
+			WHERE entry_id IN (SELECT id FROM (` + strings.TrimSuffix(strings.TrimSpace(entryQuery), ";") + `))
+			ORDER BY entry_id, start;`
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	entryIndex := make(map[int64]int, len(entries))
+	for i, entry := range entries {
+		entryIndex[entry.ID] = i
+	}
+	for rows.Next() {
+		var entryID, start, startOffset int64
+		var finish, finishOffset sql.NullInt64
+		err := rows.Scan(&entryID, &start, &startOffset, &finish, &finishOffset)
+		if err != nil {
+			return err
+		}
+		i, ok := entryIndex[entryID]
+		if !ok {
+			continue
+		}
+		pause := Pause{Begin: timeWithOffset(start, startOffset)}
+		if finish.Valid {
+			pause.Finish = timeWithOffset(finish.Int64, finishOffset.Int64)
+		}
+		entries[i].Pauses = append(entries[i].Pauses, pause)
+	}
+	return rows.Err()
+}
+
+// queryEntry is queryEntries for a single entry, sql.ErrNoRows if there is none.
+func (db *Database) queryEntry(query string, args ...any) (*Entry, error) {
+	entries, err := db.queryEntries(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &entries[0], nil
+}
+
+// ErrEntryAlreadyRunning is returned when a running entry is added while another one is still running.
+var ErrEntryAlreadyRunning = errors.New("a task is already running")
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// AddEntry inserts the entry. Adding a running entry is refused with ErrEntryAlreadyRunning
+// if there already is one; check and insert happen in one IMMEDIATE transaction and
+// the 'entries_single_running' index backs this up, so two terminals can not both win.
+// Parallel entries ('zeit track --parallel') are the exception, they may run next to others.
+// Overlapping other entries is handled according to 'ZEIT_OVERLAP', see checkOverlaps,
+// the entries it was saved over are returned.
+func (db *Database) AddEntry(entry *Entry, running bool) ([]Entry, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
-	args := []any{
-		entry.Date,
-		entry.Begin.Truncate(0).String(),
-		entry.Finish.Truncate(0).String(),
-		entry.Hours.String(),
-		entry.Project,
-		strings.ReplaceAll(entry.Task, `'`,`"`),
-		entry.Notes,
-		running}
-	query := fmt.Sprintf(`INSERT INTO entries(date, start, finish, hours, project, task, notes, running) 
-		VALUES('%s','%s','%s','%s','%s','%s','%s', '%t');`, args...)
-	result, err := db.DB.ExecContext(ctx, query)
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	overlapping, err := checkOverlaps(ctx, tx, *entry)
+	if err != nil {
+		return nil, err
+	}
+	err = insertEntry(ctx, tx, entry, running)
+	if err != nil {
+		return nil, err
+	}
+	return overlapping, tx.Commit()
+}
+
+func insertEntry(ctx context.Context, tx execer, entry *Entry, running bool) error {
+	if running && !entry.Parallel {
+		var runningEntries int
+		err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM entries WHERE running = 1;`).Scan(&runningEntries)
+		if err != nil {
+			return err
+		}
+		if runningEntries > 0 {
+			return ErrEntryAlreadyRunning
+		}
+	}
+	query := `INSERT INTO entries(date, start, start_offset, finish, finish_offset, seconds, project, project_key, task, task_key, notes, pomodoro, parallel, running, billing_state, invoice_number)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+	row := NewEntryRow(*entry)
+	result, err := tx.ExecContext(ctx, query,
+		row.Date,
+		row.Start,
+		row.StartOffset,
+		row.Finish,
+		row.FinishOffset,
+		row.Seconds,
+		row.Project,
+		GetIdFromName(row.Project),
+		row.Task,
+		GetIdFromName(row.Task),
+		row.Notes,
+		row.Pomodoro,
+		row.Parallel,
+		running,
+		row.BillingState,
+		row.InvoiceNumber)
 	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return ErrEntryAlreadyRunning
+		}
 		return err
 	}
 	entryId, err := result.LastInsertId()
@@ -60,275 +340,300 @@ func (db *Database) AddEntry(entry *Entry, running bool) error {
 		return err
 	}
 	entry.ID = entryId
-	entry.Running = true
+	entry.Running = running
+	return insertPauses(ctx, tx, entry.ID, entry.Pauses)
+}
+
+func insertPauses(ctx context.Context, tx execer, entryID int64, pauses []Pause) error {
+	for _, pause := range pauses {
+		start, startOffset := unixWithOffset(pause.Begin)
+		var finish, finishOffset sql.NullInt64
+		if !pause.Finish.IsZero() {
+			finish.Int64, finishOffset.Int64 = unixWithOffset(pause.Finish)
+			finish.Valid, finishOffset.Valid = true, true
+		}
+		_, err := tx.ExecContext(ctx, `INSERT INTO pauses(entry_id, start, start_offset, finish, finish_offset) VALUES(?, ?, ?, ?, ?);`,
+			entryID, start, startOffset, finish, finishOffset)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (db *Database) GetEntry(id int64) (*Entry, error) {
-	query := fmt.Sprintf(`SELECT * FROM entries WHERE id = '%d';`, id)
+	return db.queryEntry(`SELECT `+entryColumns+` FROM entries WHERE id = ?;`, id)
+}
+
+// UpdateEntry stores all fields of the entry and replaces its pauses, overlaps are handled like in AddEntry.
+func (db *Database) UpdateEntry(entry Entry) ([]Entry, error) {
+	query := `UPDATE entries
+				SET date = ?,
+					start = ?,
+					start_offset = ?,
+					finish = ?,
+					finish_offset = ?,
+					seconds = ?,
+					project = ?,
+					project_key = ?,
+					task = ?,
+					task_key = ?,
+					notes = ?,
+					pomodoro = ?,
+					parallel = ?,
+					running = ?,
+					billing_state = ?,
+					invoice_number = ?
+			WHERE id = ?;`
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
-	var entryDB EntryDB
-	err := db.DB.QueryRowContext(ctx, query).Scan(
-		&entryDB.ID,
-		&entryDB.Date,
-		&entryDB.Begin,
-		&entryDB.Finish,
-		&entryDB.Hours,
-		&entryDB.Project,
-		&entryDB.Task,
-		&entryDB.Notes,
-		&entryDB.Running)
+	tx, err := db.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
-	entry, err := entryDB.ConvertToEntry()
+	defer tx.Rollback()
+	overlapping, err := checkOverlaps(ctx, tx, entry)
 	if err != nil {
 		return nil, err
 	}
-	return entry, nil
-}
-
-func (db *Database) UpdateEntry(entry Entry) error {
-	args := []any{entry.Date, entry.Begin.String(), entry.Finish.String(), entry.Hours.String(), entry.Project, entry.Task, entry.Notes, entry.Running, entry.ID}
-	query := fmt.Sprintf(`UPDATE entries 
-				SET date = '%s',
-					start = '%s',
-					finish = '%s',
-					hours = '%s',
-					project = '%s',
-					task = '%s',
-					notes = '%s',
-					running = '%t'
-			WHERE id = %d;`, args...)
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-	_, err := db.DB.ExecContext(ctx, query)
+	row := NewEntryRow(entry)
+	_, err = tx.ExecContext(ctx, query,
+		row.Date,
+		row.Start,
+		row.StartOffset,
+		row.Finish,
+		row.FinishOffset,
+		row.Seconds,
+		row.Project,
+		GetIdFromName(row.Project),
+		row.Task,
+		GetIdFromName(row.Task),
+		row.Notes,
+		row.Pomodoro,
+		row.Parallel,
+		row.Running,
+		row.BillingState,
+		row.InvoiceNumber,
+		row.ID)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	_, err = tx.ExecContext(ctx, `DELETE FROM pauses WHERE entry_id = ?;`, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = insertPauses(ctx, tx, entry.ID, entry.Pauses)
+	if err != nil {
+		return nil, err
+	}
+	return overlapping, tx.Commit()
 }
 
+// AddFinishToEntry stores what finishing a running entry changes: finish, hours, notes and if it was a completed pomodoro.
 func (db *Database) AddFinishToEntry(entry Entry) error {
-	query := fmt.Sprintf(`UPDATE entries SET finish = '%s', running = false WHERE id = '%d';`, entry.Finish, entry.ID)
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
-	_, err := db.DB.ExecContext(ctx, query)
+	return finishEntry(ctx, db.DB, entry)
+}
+
+func finishEntry(ctx context.Context, tx execer, entry Entry) error {
+	query := `UPDATE entries SET finish = ?, finish_offset = ?, seconds = ?, notes = ?, pomodoro = ?, running = 0 WHERE id = ?;`
+	row := NewEntryRow(entry)
+	_, err := tx.ExecContext(ctx, query, row.Finish, row.FinishOffset, row.Seconds, row.Notes, row.Pomodoro, row.ID)
 	if err != nil {
 		return err
 	}
-	return nil
+	// A pause that is still open ends together with the entry.
+	_, err = tx.ExecContext(ctx, `UPDATE pauses SET finish = ?, finish_offset = ? WHERE entry_id = ? AND finish IS NULL;`,
+		row.Finish, row.FinishOffset, row.ID)
+	return err
 }
 
-func (db *Database) DeleteEntry(id int64) error {
-	query := fmt.Sprintf(`DELETE FROM entries WHERE id = '%d';`, id)
+// PauseEntry starts a pause of the running entry at the given time.
+func (db *Database) PauseEntry(entry Entry, at time.Time) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
-	_, err := db.DB.ExecContext(ctx, query)
-	if err != nil {
-		return err
-	}
-	return nil
+	start, startOffset := unixWithOffset(at)
+	_, err := db.DB.ExecContext(ctx, `INSERT INTO pauses(entry_id, start, start_offset) VALUES(?, ?, ?);`,
+		entry.ID, start, startOffset)
+	return err
 }
 
-func (db *Database) GetRunningEntry() (*Entry, error) {
-	// We have to make sure that NEVER two entries can be 'running = true'
-	query := `SELECT * FROM entries WHERE running = 'true';`
+// ResumeEntry ends the open pause of the entry at the given time.
+func (db *Database) ResumeEntry(entry Entry, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	finish, finishOffset := unixWithOffset(at)
+	_, err := db.DB.ExecContext(ctx, `UPDATE pauses SET finish = ?, finish_offset = ? WHERE entry_id = ? AND finish IS NULL;`,
+		finish, finishOffset, entry.ID)
+	return err
+}
+
+// SwitchEntry finishes the running entry and starts next in a single transaction,
+// so either both happen or neither does. The entries next was started over are returned like in AddEntry.
+func (db *Database) SwitchEntry(running Entry, next *Entry) ([]Entry, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
-	var entryDB EntryDB
-	err := db.DB.QueryRowContext(ctx, query).Scan(
-		&entryDB.ID,
-		&entryDB.Date,
-		&entryDB.Begin,
-		&entryDB.Finish,
-		&entryDB.Hours,
-		&entryDB.Project,
-		&entryDB.Task,
-		&entryDB.Notes,
-		&entryDB.Running)
+	tx, err := db.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
-	entry, err := entryDB.ConvertToEntry()
+	defer tx.Rollback()
+	err = finishEntry(ctx, tx, running)
 	if err != nil {
 		return nil, err
 	}
-	return entry, nil
+	overlapping, err := checkOverlaps(ctx, tx, *next)
+	if err != nil {
+		return nil, err
+	}
+	err = insertEntry(ctx, tx, next, true)
+	if err != nil {
+		return nil, err
+	}
+	return overlapping, tx.Commit()
 }
 
-func (db *Database) GetAllEntries() ([]Entry, error) {
-	query := `SELECT * FROM entries;`
+func (db *Database) DeleteEntry(id int64) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
-	rows, err := db.DB.QueryContext(ctx, query)
+	tx, err := db.DB.BeginTx(ctx, nil)
 	if err != nil {
-		fmt.Printf("Got an error reading all entries. Error: %s\n", err.Error())
-		return nil, err
+		return err
 	}
-	var entries []Entry
-	for rows.Next() {
-		var entryDB EntryDB
-		err := rows.Scan(
-			&entryDB.ID,
-			&entryDB.Date,
-			&entryDB.Begin,
-			&entryDB.Finish,
-			&entryDB.Hours,
-			&entryDB.Project,
-			&entryDB.Task,
-			&entryDB.Notes,
-			&entryDB.Running)
-		if err != nil {
-			return nil, err
-		}
-		entry, err := entryDB.ConvertToEntry()
-		if err != nil {
-			return nil, err
-		}
-		entries = append(entries, *entry)
+	defer tx.Rollback()
+	_, err = tx.ExecContext(ctx, `DELETE FROM pauses WHERE entry_id = ?;`, id)
+	if err != nil {
+		return err
 	}
-	return entries, nil
+	_, err = tx.ExecContext(ctx, `DELETE FROM entries WHERE id = ?;`, id)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (db *Database) GetEntriesViaProject(project string) ([]Entry, error) {
-	query := fmt.Sprintf(`SELECT * FROM entries WHERE project = '%s';`, project)
+func (db *Database) GetRunningEntry() (*Entry, error) {
+	// NEVER two entries can be 'running = true', the 'entries_single_running' index makes sure of that.
+	return db.queryEntry(`SELECT ` + entryColumns + ` FROM entries WHERE running = 1 LIMIT 1;`)
+}
+
+func (db *Database) GetRunningEntries() ([]Entry, error) {
+	return db.queryEntries(`SELECT ` + entryColumns + ` FROM entries WHERE running = 1 ORDER BY start;`)
+}
+
+// EnsureSingleRunningIndex creates the index that keeps more than one entry from running.
+// It fails as long as the database still contains several running entries.
+func (db *Database) EnsureSingleRunningIndex() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
-	rows, err := db.DB.QueryContext(ctx, query)
+	_, err := db.DB.ExecContext(ctx, singleRunningIndexQuery)
+	return err
+}
+
+func (db *Database) GetAllEntries() ([]Entry, error) {
+	entries, err := db.QueryEntries(EntryQuery{})
 	if err != nil {
-		rows.Close()
+		fmt.Printf("Got an error reading all entries. Error: %s\n", err.Error())
 		return nil, err
 	}
-	var entries []Entry
-	for rows.Next() {
-		var entryDB EntryDB
-		err := rows.Scan(
-			&entryDB.ID,
-			&entryDB.Date,
-			&entryDB.Begin,
-			&entryDB.Finish,
-			&entryDB.Hours,
-			&entryDB.Project,
-			&entryDB.Task,
-			&entryDB.Notes,
-			&entryDB.Running)
-		if err != nil {
-			return nil, err
-		}
-		entry, err := entryDB.ConvertToEntry()
-		if err != nil {
-			return nil, err
-		}
-		entries = append(entries, *entry)
-	}
 	return entries, nil
 }
 
+func (db *Database) GetEntriesViaProject(project string) ([]Entry, error) {
+	return db.QueryEntries(EntryQuery{Project: project})
+}
+
 func (db *Database) GetEntriesBeforeDate(date time.Time) ([]Entry, error) {
-	query := fmt.Sprintf(`SELECT * FROM entries WHERE start < '%s';`, date.String())
+	return db.queryEntries(`SELECT `+entryColumns+` FROM entries WHERE start < ?;`, date.Unix())
+}
+
+func (db *Database) GetEntriesAfterDate(date time.Time) ([]Entry, error) {
+	return db.queryEntries(`SELECT `+entryColumns+` FROM entries WHERE start > ?;`, date.Unix())
+}
+
+// It is possible to filter this for projects
+func (db *Database) GetEntriesPerDay(project string) ([]EntriesGroupedByDay, error) {
+	query := `SELECT date, COUNT(DISTINCT(project)), COUNT(DISTINCT(task)), SUM(seconds)
+				FROM entries
+				WHERE ((project = ?) or ? = '')
+				GROUP BY date;`
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
-	rows, err := db.DB.QueryContext(ctx, query)
+	rows, err := db.DB.QueryContext(ctx, query, project, project)
 	if err != nil {
-		rows.Close()
 		return nil, err
 	}
-	var entries []Entry
+	defer rows.Close()
+	var EGBY []EntriesGroupedByDay
 	for rows.Next() {
-		var entryDB EntryDB
+		var groupedEntry EntriesGroupedByDay
+		var seconds int64
 		err := rows.Scan(
-			&entryDB.ID,
-			&entryDB.Date,
-			&entryDB.Begin,
-			&entryDB.Finish,
-			&entryDB.Hours,
-			&entryDB.Project,
-			&entryDB.Task,
-			&entryDB.Notes,
-			&entryDB.Running)
-		if err != nil {
-			return nil, err
-		}
-		entry, err := entryDB.ConvertToEntry()
+			&groupedEntry.Date,
+			&groupedEntry.Projects,
+			&groupedEntry.Tasks,
+			&seconds,
+		)
 		if err != nil {
 			return nil, err
 		}
-		entries = append(entries, *entry)
+		groupedEntry.Hours = secondsToHours(seconds)
+		EGBY = append(EGBY, groupedEntry)
 	}
-	return entries, nil
+	return EGBY, rows.Err()
 }
 
-func (db *Database) GetEntriesAfterDate(date time.Time) ([]Entry, error) {
-	query := fmt.Sprintf(`SELECT * FROM entries WHERE start > '%s';`, date.String())
+// GetRecentProjectTasks returns the last limit distinct project/task pairs, most recently tracked first.
+func (db *Database) GetRecentProjectTasks(limit int) ([]ProjectTask, error) {
+	query := `SELECT project, task FROM entries
+				GROUP BY project, task
+				ORDER BY MAX(start) DESC
+				LIMIT ?;`
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
-	rows, err := db.DB.QueryContext(ctx, query)
+	rows, err := db.DB.QueryContext(ctx, query, limit)
 	if err != nil {
-		rows.Close()
 		return nil, err
 	}
-	var entries []Entry
+	defer rows.Close()
+	var projectTasks []ProjectTask
 	for rows.Next() {
-		var entryDB EntryDB
-		err := rows.Scan(
-			&entryDB.ID,
-			&entryDB.Date,
-			&entryDB.Begin,
-			&entryDB.Finish,
-			&entryDB.Hours,
-			&entryDB.Project,
-			&entryDB.Task,
-			&entryDB.Notes,
-			&entryDB.Running)
-		if err != nil {
-			return nil, err
-		}
-		entry, err := entryDB.ConvertToEntry()
+		var projectTask ProjectTask
+		err := rows.Scan(&projectTask.Project, &projectTask.Task)
 		if err != nil {
 			return nil, err
 		}
-		entries = append(entries, *entry)
+		projectTasks = append(projectTasks, projectTask)
 	}
-	return entries, nil
+	return projectTasks, rows.Err()
 }
 
-// It is possible to filter this for projects
-func (db *Database) GetEntriesPerDay(project string) ([]EntriesGroupedByDay, error) {
-	query := fmt.Sprintf(`SELECT date, COUNT(DISTINCT(project)), COUNT(DISTINCT(task)), SUM(hours) 
-				FROM entries 
-				WHERE ((project = '%s') or '%s' = '')
-				GROUP BY date;`, project, project)
+// GetPomodorosPerDay counts the completed pomodoros of every day among the entries the query selects.
+func (db *Database) GetPomodorosPerDay(query EntryQuery) ([]PomodorosOfDay, error) {
+	conditions, args := query.conditions()
+	conditions = append(conditions, "pomodoro = 1")
+	pomodoroQuery := `SELECT date, COUNT(*) FROM entries
+				WHERE ` + strings.Join(conditions, " AND ") + `
+				GROUP BY date
+				ORDER BY MIN(start);`
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
-	rows, err := db.DB.QueryContext(ctx, query)
+	rows, err := db.DB.QueryContext(ctx, pomodoroQuery, args...)
 	if err != nil {
-		rows.Close()
 		return nil, err
 	}
-	var EGBY []EntriesGroupedByDay
+	defer rows.Close()
+	var pomodoros []PomodorosOfDay
 	for rows.Next() {
-		var groupedEntry EntriesGroupedByDay
-		var hoursStr string
-		err := rows.Scan(
-			&groupedEntry.Date,
-			&groupedEntry.Projects,
-			&groupedEntry.Tasks,
-			&hoursStr,
-		)
+		var day PomodorosOfDay
+		err := rows.Scan(&day.Date, &day.Count)
 		if err != nil {
 			return nil, err
 		}
-		hoursDec, err := decimal.NewFromString(hoursStr)
-		if err != nil {
-			fmt.Printf("Could not convert hours from str to decimal. Error: %s", err.Error())
-			return nil, err
-		}
-		groupedEntry.Hours = hoursDec
-		EGBY = append(EGBY, groupedEntry)
+		pomodoros = append(pomodoros, day)
 	}
-	return EGBY, nil
+	return pomodoros, rows.Err()
 }
 
 func (db *Database) GetUniqueProjects() ([]string, error) {
@@ -337,9 +642,9 @@ func (db *Database) GetUniqueProjects() ([]string, error) {
 	defer cancel()
 	rows, err := db.DB.QueryContext(ctx, query)
 	if err != nil {
-		rows.Close()
 		return nil, err
 	}
+	defer rows.Close()
 	var projects []string
 	for rows.Next() {
 		var project string
@@ -349,25 +654,27 @@ func (db *Database) GetUniqueProjects() ([]string, error) {
 		}
 		projects = append(projects, project)
 	}
-	return projects, nil
-}
-func createDefaultTables(db *sql.DB) error {
-	query := `CREATE TABLE IF NOT EXISTS entries(
-			ID INTEGER PRIMARY KEY AUTOINCREMENT,
-			date  TEXT NOT NULL,
-			start TEXT NOT NULL,
-			finish TEXT,
-			hours  FLOAT,
-			project TEXT NOT NULL,
-			task   TEXT NOT NULL,
-			notes  TEXT,
-			running BOOL);`
+	return projects, rows.Err()
+}
+
+// GetUniqueTasks returns the distinct tasks of the project, of all projects if it is empty.
+func (db *Database) GetUniqueTasks(project string) ([]string, error) {
+	query := `SELECT DISTINCT task FROM entries WHERE ? = '' OR project = ? ORDER BY task;`
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
-	_, err := db.ExecContext(ctx, query)
+	rows, err := db.DB.QueryContext(ctx, query, project, project)
 	if err != nil {
-		fmt.Printf("error while making default table. Error: %s", err.Error())
-		return err
+		return nil, err
 	}
-	return nil
+	defer rows.Close()
+	var tasks []string
+	for rows.Next() {
+		var task string
+		err := rows.Scan(&task)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
 }
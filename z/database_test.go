@@ -0,0 +1,58 @@
+package z
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEntryQuotesRoundTrip(t *testing.T) {
+	db := newTestDatabase(t)
+	begin := time.Date(2024, 8, 22, 9, 0, 0, 0, time.UTC)
+	entry := Entry{
+		Project: `O'Reilly "Books"`,
+		Task:    `it's a "quoted" task`,
+		Notes:   "line one's\n\"line\" two\\n'); DROP TABLE entries; --",
+		Begin:   begin,
+		Finish:  begin.Add(time.Hour),
+	}
+	entry.SetDateFromBegining()
+	entry.Hours = entry.GetDuration()
+	_, err := db.AddEntry(&entry, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := db.GetEntry(entry.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Project != entry.Project || stored.Task != entry.Task || stored.Notes != entry.Notes {
+		t.Errorf("stored entry is %q / %q / %q, want %q / %q / %q",
+			stored.Project, stored.Task, stored.Notes, entry.Project, entry.Task, entry.Notes)
+	}
+
+	stored.Task = `won't "change"`
+	stored.Notes = `'' "" \' \"`
+	_, err = db.UpdateEntry(*stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := db.QueryEntries(EntryQuery{Project: entry.Project, Task: stored.Task})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("found %d entries of the updated task, want 1", len(entries))
+	}
+	if entries[0].Task != stored.Task || entries[0].Notes != stored.Notes {
+		t.Errorf("updated entry is %q / %q, want %q / %q", entries[0].Task, entries[0].Notes, stored.Task, stored.Notes)
+	}
+
+	tasks, err := db.GetUniqueTasks(entry.Project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0] != stored.Task {
+		t.Errorf("unique tasks of %q are %q, want %q", entry.Project, tasks, stored.Task)
+	}
+}
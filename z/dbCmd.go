@@ -0,0 +1,73 @@
+package z
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+var dryRun bool
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage the zeit database",
+	Long:  "Manage the zeit database and its schema.",
+	// Replaces prepareDatabase of the root command, 'db migrate' has to see the pending migrations itself.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending schema migrations",
+	Long: `Apply all pending schema migrations to the database.
+
+Migrations are applied automatically before every other command,
+unless 'ZEIT_AUTO_MIGRATE=false' is set. A backup of the database file
+is taken before the first pending migration is applied.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		version, err := database.SchemaVersion()
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		pending, err := database.PendingMigrations()
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		if len(pending) == 0 {
+			fmt.Printf("%s database is up to date at schema version %d\n", CharInfo, version)
+			return
+		}
+		if dryRun {
+			fmt.Printf("%s database is at schema version %d, would apply:\n", CharInfo, version)
+			for _, m := range pending {
+				fmt.Printf("%s %s %s\n", CharMore, color.FgLightWhite.Render(m.Version), m.Description)
+			}
+			return
+		}
+		applied, err := database.Migrate()
+		for _, m := range applied {
+			fmt.Printf("%s applied %s %s\n", CharInfo, color.FgLightWhite.Render(m.Version), m.Description)
+		}
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbMigrateCmd)
+	dbMigrateCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only list the migrations that would be applied")
+
+	var err error
+	database, err = InitDB()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,182 @@
+package z
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Kinds of project rules, what their pattern is matched against.
+const (
+	RuleDir    = "dir"
+	RuleRemote = "remote"
+)
+
+// ProjectRule picks the project for activities tracked in a directory or a repository with a remote.
+// A dir rule is a glob matched against the directory and each of its parents, like '/home/me/src/acme/*'.
+// In a remote rule '*' matches any text, like '*github.com/acme/*'.
+type ProjectRule struct {
+	ID      int64
+	Kind    string
+	Pattern string
+	Project string
+}
+
+// Detection is the project detected for a directory and why.
+type Detection struct {
+	Project string
+	// Rule is the rule that matched, nil if it was the repository mapped via 'zeit repo'.
+	Rule *ProjectRule
+	// Matched is the directory, repository or remote URL that matched.
+	Matched string
+}
+
+// ValidateProjectRule checks that the pattern of rule can be matched.
+func ValidateProjectRule(rule ProjectRule) error {
+	switch rule.Kind {
+	case RuleDir:
+		_, err := filepath.Match(rule.Pattern, "")
+		return err
+	case RuleRemote:
+		return nil
+	}
+	return fmt.Errorf("unknown kind of rule '%s'", rule.Kind)
+}
+
+// matchDir returns the directory, dir itself or one of its parents, the dir rule matches.
+func (rule ProjectRule) matchDir(dir string) (string, bool) {
+	for {
+		if ok, _ := filepath.Match(rule.Pattern, dir); ok {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// matchRemote returns the first of the remote URLs the remote rule matches.
+func (rule ProjectRule) matchRemote(urls []string) (string, bool) {
+	pattern := regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(rule.Pattern), `\*`, ".*") + "$")
+	for _, url := range urls {
+		if pattern.MatchString(url) {
+			return url, true
+		}
+	}
+	return "", false
+}
+
+// gitRemoteURLs returns the URLs of all remotes of repo.
+func gitRemoteURLs(repo string) []string {
+	// git fails if there is no remote at all.
+	output, err := git(repo, "config", "--get-regexp", `^remote\..*\.url$`)
+	if err != nil {
+		return nil
+	}
+	var urls []string
+	for _, line := range strings.Split(output, "\n") {
+		_, url, ok := strings.Cut(line, " ")
+		if ok {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// DetectProject finds the project for activities tracked in dir, nil if there is none.
+// A repository mapped to a single project via 'zeit repo' comes first,
+// then the dir rules and then the remote rules, each in the order they were added.
+func DetectProject(dir string) (*Detection, error) {
+	repo, err := GitRepoRoot(dir)
+	isRepo := err == nil
+	if isRepo {
+		repoProject, err := ProjectOfRepo(repo)
+		if err != nil {
+			return nil, err
+		}
+		if repoProject != "" {
+			return &Detection{Project: repoProject, Matched: repo}, nil
+		}
+	}
+
+	rules, err := database.GetProjectRules()
+	if err != nil {
+		return nil, err
+	}
+	for i, rule := range rules {
+		if rule.Kind != RuleDir {
+			continue
+		}
+		if matched, ok := rule.matchDir(dir); ok {
+			return &Detection{Project: rule.Project, Rule: &rules[i], Matched: matched}, nil
+		}
+	}
+	if !isRepo {
+		return nil, nil
+	}
+	urls := gitRemoteURLs(repo)
+	for i, rule := range rules {
+		if rule.Kind != RuleRemote {
+			continue
+		}
+		if matched, ok := rule.matchRemote(urls); ok {
+			return &Detection{Project: rule.Project, Rule: &rules[i], Matched: matched}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (db *Database) AddProjectRule(rule *ProjectRule) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	result, err := db.DB.ExecContext(ctx, `INSERT INTO project_rules(kind, pattern, project) VALUES(?, ?, ?);`, rule.Kind, rule.Pattern, rule.Project)
+	if err != nil {
+		return err
+	}
+	rule.ID, err = result.LastInsertId()
+	return err
+}
+
+func (db *Database) GetProjectRules() ([]ProjectRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	rows, err := db.DB.QueryContext(ctx, `SELECT id, kind, pattern, project FROM project_rules ORDER BY id;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var rules []ProjectRule
+	for rows.Next() {
+		var rule ProjectRule
+		err := rows.Scan(&rule.ID, &rule.Kind, &rule.Pattern, &rule.Project)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// DeleteProjectRule removes the rule, sql.ErrNoRows if there is none with the id.
+func (db *Database) DeleteProjectRule(id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	result, err := db.DB.ExecContext(ctx, `DELETE FROM project_rules WHERE id = ?;`, id)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
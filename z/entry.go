@@ -1,7 +1,9 @@
 package z
 
 import (
+	"database/sql"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -12,15 +14,21 @@ import (
 )
 
 type Entry struct {
-	ID      int64           `json:"-"`
-	Date    string          `json:"date,omitempty"`
-	Begin   time.Time       `json:"begin,omitempty"`
-	Finish  time.Time       `json:"finish,omitempty"`
-	Project string          `json:"project,omitempty"`
-	Hours   decimal.Decimal `json:"hours,omitempty"`
-	Task    string          `json:"task,omitempty"`
-	Notes   string          `json:"notes,omitempty"`
-	Running bool            `json:"-"`
+	ID       int64           `json:"-"`
+	Date     string          `json:"date,omitempty"`
+	Begin    time.Time       `json:"begin,omitempty"`
+	Finish   time.Time       `json:"finish,omitempty"`
+	Project  string          `json:"project,omitempty"`
+	Hours    decimal.Decimal `json:"hours,omitempty"`
+	Task     string          `json:"task,omitempty"`
+	Notes    string          `json:"notes,omitempty"`
+	Pauses   []Pause         `json:"pauses,omitempty"`
+	Pomodoro bool            `json:"pomodoro,omitempty"`
+	Parallel bool            `json:"parallel,omitempty"`
+	Running  bool            `json:"-"`
+	// BillingState is one of the Billing constants, InvoiceNumber is set while it is BillingInvoiced.
+	BillingState  string `json:"billing_state,omitempty"`
+	InvoiceNumber string `json:"invoice_number,omitempty"`
 }
 
 type EntryDB struct {
@@ -32,7 +40,11 @@ type EntryDB struct {
 	Hours   string
 	Task    string
 	Notes   string
+	Pauses  []Pause
 	Running bool
+	// Only kept for invoices this database knows, see importBilling.
+	BillingState  string `json:"billing_state"`
+	InvoiceNumber string `json:"invoice_number"`
 }
 
 func (edb *EntryDB) ConvertToEntry() (*Entry, error) {
@@ -41,11 +53,11 @@ func (edb *EntryDB) ConvertToEntry() (*Entry, error) {
 	if err != nil {
 		return nil, err
 	}
-	beginParsed, err := dateparse.ParseAny(edb.Begin)
+	beginParsed, err := parseEntryTime(edb.Begin)
 	if err != nil {
 		return nil, err
 	}
-	finishParsed, err := dateparse.ParseAny(edb.Finish)
+	finishParsed, err := parseEntryTime(edb.Finish)
 	if err != nil {
 		return nil, err
 
@@ -62,12 +74,124 @@ func (edb *EntryDB) ConvertToEntry() (*Entry, error) {
 	entry.Hours = hoursParsed
 	entry.Task = edb.Task
 	entry.Notes = edb.Notes
+	entry.Pauses = edb.Pauses
 	entry.Running = edb.Running
+	entry.BillingState = edb.BillingState
+	entry.InvoiceNumber = edb.InvoiceNumber
 
 	return &entry, nil
 
 }
 
+// EntryRow is the canonical layout of an Entry in the entries table:
+// begin/finish as UTC unix seconds together with the UTC offset they were tracked in,
+// and the duration as whole seconds so it can be summed up exactly in SQL.
+type EntryRow struct {
+	ID           int64
+	Date         string
+	Start        int64
+	StartOffset  int64
+	Finish       sql.NullInt64
+	FinishOffset sql.NullInt64
+	Seconds      int64
+	Project      string
+	Task         string
+	Notes        string
+	Pomodoro     bool
+	Parallel     bool
+	Running      bool
+
+	BillingState  string
+	InvoiceNumber sql.NullString
+}
+
+func NewEntryRow(entry Entry) EntryRow {
+	row := EntryRow{
+		ID:       entry.ID,
+		Date:     entry.Date,
+		Seconds:  hoursToSeconds(entry.Hours),
+		Project:  entry.Project,
+		Task:     entry.Task,
+		Notes:    entry.Notes,
+		Pomodoro: entry.Pomodoro,
+		Parallel: entry.Parallel,
+		Running:  entry.Running,
+	}
+	row.BillingState = entry.BillingState
+	if row.BillingState == "" {
+		row.BillingState = BillingUnbilled
+	}
+	row.InvoiceNumber = sql.NullString{String: entry.InvoiceNumber, Valid: entry.InvoiceNumber != ""}
+	row.Start, row.StartOffset = unixWithOffset(entry.Begin)
+	if !entry.Finish.IsZero() {
+		finish, finishOffset := unixWithOffset(entry.Finish)
+		row.Finish = sql.NullInt64{Int64: finish, Valid: true}
+		row.FinishOffset = sql.NullInt64{Int64: finishOffset, Valid: true}
+	}
+	return row
+}
+
+func (row *EntryRow) ConvertToEntry() *Entry {
+	entry := Entry{
+		ID:       row.ID,
+		Date:     row.Date,
+		Begin:    timeWithOffset(row.Start, row.StartOffset),
+		Hours:    secondsToHours(row.Seconds),
+		Project:  row.Project,
+		Task:     row.Task,
+		Notes:    row.Notes,
+		Pomodoro: row.Pomodoro,
+		Parallel: row.Parallel,
+		Running:  row.Running,
+	}
+	entry.BillingState = row.BillingState
+	entry.InvoiceNumber = row.InvoiceNumber.String
+	if row.Finish.Valid {
+		entry.Finish = timeWithOffset(row.Finish.Int64, row.FinishOffset.Int64)
+	}
+	return &entry
+}
+
+func unixWithOffset(t time.Time) (int64, int64) {
+	_, offset := t.Zone()
+	return t.Unix(), int64(offset)
+}
+
+// timeWithOffset restores a stored timestamp in the offset it was tracked in.
+// If that offset is the one of the local time zone at that instant, the local zone is used instead.
+func timeWithOffset(unix int64, offset int64) time.Time {
+	t := time.Unix(unix, 0)
+	if _, localOffset := t.Zone(); int64(localOffset) == offset {
+		return t
+	}
+	return t.In(time.FixedZone("", int(offset)))
+}
+
+func hoursToSeconds(hours decimal.Decimal) int64 {
+	return hours.Mul(decimal.NewFromInt(3600)).Round(0).IntPart()
+}
+
+func secondsToHours(seconds int64) decimal.Decimal {
+	return decimal.NewFromInt(seconds).Div(decimal.NewFromInt(3600))
+}
+
+// time.Time.String() appends the monotonic clock reading (e.g. "m=+0.000123") which dateparse does not understand.
+var monotonicClockSuffix = regexp.MustCompile(`\s+m=[+-][0-9.]+$`)
+
+func parseEntryTime(value string) (time.Time, error) {
+	return dateparse.ParseAny(monotonicClockSuffix.ReplaceAllString(strings.TrimSpace(value), ""))
+}
+
+type ProjectTask struct {
+	Project string
+	Task    string
+}
+
+type PomodorosOfDay struct {
+	Date  string
+	Count int
+}
+
 type EntriesGroupedByDay struct {
 	Date     string
 	Projects int8
@@ -161,9 +285,7 @@ func (entry *Entry) GetOutputForTrack(isRunning bool, wasRunning bool) string {
 	var outputSuffix = ""
 	var outputPrefix = ""
 
-	now := time.Now().Truncate(0)
-	trackDiffNow := now.Sub(entry.Begin)
-	durationString := fmtDuration(trackDiffNow)
+	durationString := fmtDuration(entry.ActiveDuration())
 
 	if isRunning && !wasRunning {
 		outputPrefix = "began tracking"
@@ -186,11 +308,7 @@ func (entry *Entry) GetOutputForTrack(isRunning bool, wasRunning bool) string {
 }
 
 func (entry *Entry) GetDuration() decimal.Decimal {
-	duration := entry.Finish.Sub(entry.Begin)
-	if duration < 0 {
-		duration = time.Since(entry.Begin)
-	}
-	return decimal.NewFromFloat(duration.Hours())
+	return decimal.NewFromInt(entry.ActiveDuration().Nanoseconds()).Div(decimal.NewFromInt(int64(time.Hour)))
 }
 
 func (entry *Entry) ConvertToCSVAllData() []string {
@@ -217,8 +335,7 @@ func (entry *Entry) ConvertToCSVShortData() []string {
 func (entry *Entry) GetOutputForFinish() string {
 	var outputSuffix = ""
 
-	trackDiff := entry.Finish.Sub(entry.Begin)
-	taskDuration := fmtDuration(trackDiff)
+	taskDuration := fmtDuration(entry.ActiveDuration())
 
 	outputSuffix = fmt.Sprintf(" for %sh", color.FgLightWhite.Render(taskDuration))
 
@@ -241,12 +358,14 @@ func (entry *Entry) GetOutput(full bool) string {
 	if entry.Finish.IsZero() {
 		entryFinish = time.Now().Truncate(0)
 		isRunning = "[running]"
+		if entry.IsPaused() {
+			isRunning = "[paused]"
+		}
 	} else {
 		entryFinish = entry.Finish
 	}
 
-	trackDiff := entryFinish.Sub(entry.Begin)
-	taskDuration := fmtDuration(trackDiff)
+	taskDuration := fmtDuration(entry.ActiveDuration())
 	if !full {
 
 		output = fmt.Sprintf("%s %s on %s from %s to %s (%sh) %s",
@@ -274,32 +393,6 @@ func (entry *Entry) GetOutput(full bool) string {
 	return output
 }
 
-func GetFilteredEntries(entries []Entry, project string, task string, since time.Time, until time.Time) ([]Entry, error) {
-	var filteredEntries []Entry
-
-	for _, entry := range entries {
-		if project != "" && GetIdFromName(entry.Project) != GetIdFromName(project) {
-			continue
-		}
-
-		if task != "" && GetIdFromName(entry.Task) != GetIdFromName(task) {
-			continue
-		}
-
-		if !since.IsZero() && !since.Before(entry.Begin) && !since.Equal(entry.Begin) {
-			continue
-		}
-
-		if !until.IsZero() && !until.After(entry.Finish) && !until.Equal(entry.Finish) {
-			continue
-		}
-
-		filteredEntries = append(filteredEntries, entry)
-	}
-
-	return filteredEntries, nil
-}
-
 // func SortEntries(entries []Entry, user, sortingType string) ([]Entry, error) {
 // 	fmt.Printf("These are the incoming entries: \n %v\n", entries)
 // 	switch sortingType {
@@ -7,9 +7,13 @@ import (
 	"strings"
 
 	"github.com/araddon/dateparse"
+	"github.com/gookit/color"
 	"github.com/spf13/cobra"
 )
 
+var entryCommits bool
+var entryBilling string
+
 var entryCmd = &cobra.Command{
 	Use:   "entry ([flags]) [id]",
 	Short: "Display or update activity",
@@ -29,6 +33,27 @@ var entryCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		updating := begin != "" || finish != "" || project != "" || task != "" || notes != "" || entryBilling != ""
+		if updating && entry.BillingState == BillingInvoiced {
+			if entryBilling != "" {
+				fmt.Printf("%s the activity is on invoice %s, void it first with 'zeit invoice void %s'.\n", CharError, entry.InvoiceNumber, entry.InvoiceNumber)
+				os.Exit(1)
+			}
+			if !force {
+				fmt.Printf("%s the activity is on invoice %s already, use --force to change it anyway.\n", CharError, entry.InvoiceNumber)
+				os.Exit(1)
+			}
+		}
+
+		switch entryBilling {
+		case "":
+		case BillingUnbilled, BillingWrittenOff:
+			entry.BillingState = entryBilling
+		default:
+			fmt.Printf("%s --billing has to be either %s or %s.\n", CharError, BillingUnbilled, BillingWrittenOff)
+			os.Exit(1)
+		}
+
 		if begin != "" {
 			entry.Begin, err = dateparse.ParseAny(begin)
 			if err != nil {
@@ -45,6 +70,14 @@ var entryCmd = &cobra.Command{
 			}
 		}
 
+		if (begin != "" || finish != "") && !entry.Finish.IsZero() {
+			if !entry.IsFinishedAfterBegan() {
+				fmt.Printf("%s the activity has to finish after it began.\n", CharError)
+				os.Exit(1)
+			}
+			entry.Hours = entry.GetDuration()
+		}
+
 		if project != "" {
 			entry.Project = project
 		}
@@ -57,12 +90,27 @@ var entryCmd = &cobra.Command{
 			entry.Notes = strings.ReplaceAll(notes, "\\n", "\n")
 		}
 
-		err = database.UpdateEntry(*entry)
-		if err != nil {
-			fmt.Printf("%s %+v\n", CharError, err)
-			os.Exit(1)
+		if updating {
+			overlapping, err := database.UpdateEntry(*entry)
+			if err != nil {
+				fmt.Printf("%s %+v\n", CharError, err)
+				os.Exit(1)
+			}
+			warnOverlaps(*entry, overlapping)
 		}
 		fmt.Printf("%s %s\n", CharInfo, entry.GetOutput(true))
+		if billing := getOutputForBilling(*entry); billing != "" {
+			fmt.Printf("   %s\n", color.FgGray.Render(billing))
+		}
+
+		if entryCommits {
+			commits, err := GetCommits(*entry)
+			if err != nil {
+				fmt.Printf("%s %+v\n", CharError, err)
+				os.Exit(1)
+			}
+			fmt.Printf("\n%s commits:\n%s", CharMore, GetOutputForCommits(commits))
+		}
 	},
 }
 
@@ -70,11 +118,18 @@ func init() {
 	rootCmd.AddCommand(entryCmd)
 	entryCmd.Flags().StringVarP(&begin, "begin", "b", "", "Update date/time the activity began at")
 	entryCmd.Flags().StringVarP(&finish, "finish", "s", "", "Update date/time the activity finished at")
+	entryCmd.Flags().StringVar(&overlapPolicy, "overlap", "", "What to do if the activity overlaps others: warn, reject or ignore (default ZEIT_OVERLAP or warn)")
 	entryCmd.Flags().StringVarP(&project, "project", "p", "", "Update activity project")
 	entryCmd.Flags().StringVarP(&notes, "notes", "n", "", "Update activity notes")
 	entryCmd.Flags().StringVarP(&task, "task", "t", "", "Update activity task")
+	entryCmd.Flags().StringVar(&entryBilling, "billing", "", "Update the billing state: unbilled or written-off")
+	entryCmd.Flags().BoolVar(&force, "force", false, "Change the activity even if it is invoiced already")
+	entryCmd.Flags().BoolVar(&entryCommits, "commits", false, "Show the commits made to the project's repositories during the activity")
 	entryCmd.Flags().BoolVar(&fractional, "decimal", true, "Show fractional hours in decimal format instead of minutes")
 
+	registerProjectTaskCompletion(entryCmd, false)
+	entryCmd.ValidArgsFunction = completeEntryID
+
 	var err error
 	database, err = InitDB()
 	if err != nil {
@@ -21,6 +21,16 @@ var eraseCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		entry, err := database.GetEntry(int64(id))
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		if entry.BillingState == BillingInvoiced && !force {
+			fmt.Printf("%s the activity is on invoice %s already, use --force to erase it anyway.\n", CharError, entry.InvoiceNumber)
+			os.Exit(1)
+		}
+
 		err = database.DeleteEntry(int64(id))
 		if err != nil {
 			fmt.Printf("%s %+v\n", CharError, err)
@@ -33,6 +43,9 @@ var eraseCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(eraseCmd)
+	eraseCmd.Flags().BoolVar(&force, "force", false, "Erase the activity even if it is invoiced already")
+
+	eraseCmd.ValidArgsFunction = completeEntryID
 
 	var err error
 	database, err = InitDB()
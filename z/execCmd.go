@@ -0,0 +1,184 @@
+package z
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+var execSwitch bool
+
+var execCmd = &cobra.Command{
+	Use:   "exec ([flags]) -- [command] ([args])",
+	Short: "Track how long a command takes",
+	Long: `Run a command and track the time it takes as an activity.
+
+The activity starts right before the command and is finished as soon as it exits.
+The command line and its exit code are added to the notes, and zeit exits with
+the exit code of the command, or 128 plus the signal that killed it.
+
+Only one activity can be running at a time: if one already is, exec refuses to
+start, unless --switch is given. Then the running activity is finished for the
+time of the command and its project, task and notes are tracked again afterwards.
+With parallel timers, the running activity to pause is picked by its id via --from.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if task == "" {
+			fmt.Printf("%s Can not track empty task.\nPlease assign a task via --task to track\n", CharError)
+			os.Exit(1)
+		}
+		if project == "" {
+			fmt.Printf("%s Can not track empty project.\nPlease assign a project via --project\n", CharError)
+			os.Exit(1)
+		}
+		commandPath, err := exec.LookPath(args[0])
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(127)
+		}
+
+		var runningEntry *Entry
+		if execSwitch {
+			runningEntry = selectEntryToSwitchFrom(switchFrom)
+		} else {
+			runningEntry, err = database.GetRunningEntry()
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				fmt.Printf("%s %+v\n", CharError, err)
+				os.Exit(1)
+			}
+		}
+		if runningEntry != nil && !execSwitch {
+			fmt.Printf("%s A task is already running, you have to finish it first before you start a new one.\n\nType 'zeit finish' to do so, or use 'zeit exec --switch'.\n", CharError)
+			os.Exit(1)
+		}
+
+		execEntry := NewEntry(project, task)
+		execEntry.Notes = notes
+		if runningEntry != nil {
+			// Switching away from a parallel timer leaves the others running, so the command runs in parallel too.
+			execEntry.Parallel = runningEntry.Parallel
+			runningEntry.Finish = execEntry.Begin
+			runningEntry.Running = false
+			runningEntry.Hours = runningEntry.GetDuration()
+			overlapping, err := database.SwitchEntry(*runningEntry, &execEntry)
+			if err != nil {
+				fmt.Printf("%s could not switch tasks, nothing was changed. Error: %s\n", CharError, err.Error())
+				os.Exit(1)
+			}
+			warnOverlaps(execEntry, overlapping)
+			fmt.Print(runningEntry.GetOutputForFinish())
+		} else {
+			startEntry(&execEntry)
+		}
+		fmt.Println(execEntry.GetStartTrackingStr())
+
+		exitCode := runTracked(commandPath, args)
+
+		execEntry.SetFinish()
+		execEntry.Hours = execEntry.GetDuration()
+		execEntry.Notes = strings.TrimSpace(fmt.Sprintf("%s\ncommand: %s\nexit code: %d", execEntry.Notes, quoteCommandLine(args), exitCode))
+		if runningEntry != nil {
+			resumedEntry := NewEntry(runningEntry.Project, runningEntry.Task)
+			resumedEntry.Parallel = runningEntry.Parallel
+			resumedEntry.Notes = runningEntry.Notes
+			resumedEntry.Begin = execEntry.Finish
+			resumedEntry.SetDateFromBegining()
+			var overlapping []Entry
+			overlapping, err = database.SwitchEntry(execEntry, &resumedEntry)
+			if err == nil {
+				warnOverlaps(resumedEntry, overlapping)
+				fmt.Print(execEntry.GetOutputForFinish())
+				fmt.Println(resumedEntry.GetStartTrackingStr())
+			}
+		} else {
+			err = database.AddFinishToEntry(execEntry)
+			if err == nil {
+				fmt.Print(execEntry.GetOutputForFinish())
+			}
+		}
+		if err != nil {
+			fmt.Printf("%s could not finish entry %d. Error: %s\n", CharError, execEntry.ID, err.Error())
+		}
+		os.Exit(exitCode)
+	},
+}
+
+// runTracked runs the command with the terminal passed through and returns its exit code.
+// Signals zeit gets in the meantime are passed on to it, so zeit always lives long enough to finish the entry.
+func runTracked(commandPath string, args []string) int {
+	child := exec.Command(commandPath, args[1:]...)
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+
+	signals, stop := notifyInterrupt()
+	defer stop()
+
+	err := child.Start()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		return 127
+	}
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				child.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+	err = child.Wait()
+	close(done)
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// Killed by a signal, reported like a shell does it.
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return 128 + int(status.Signal())
+		}
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		return 1
+	}
+	return 0
+}
+
+func quoteCommandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\$`") {
+			arg = strconv.Quote(arg)
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+	execCmd.Flags().SetInterspersed(false)
+	execCmd.Flags().StringVarP(&project, "project", "p", "", "Project to be assigned")
+	execCmd.Flags().StringVarP(&task, "task", "t", "", "Task to be assigned")
+	execCmd.Flags().StringVarP(&notes, "notes", "n", "", "Activity notes, the command line and exit code are added to them")
+	execCmd.Flags().BoolVar(&execSwitch, "switch", false, "Pause a running activity for the time of the command instead of refusing to start")
+	execCmd.Flags().Int64Var(&switchFrom, "from", 0, "Id of the running activity to pause with --switch, if several are running")
+
+	var err error
+	database, err = InitDB()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+}
@@ -74,15 +74,9 @@ var exportCmd = &cobra.Command{
 	Long:  "Export tracked activities to various formats.",
 	// Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		var entries []Entry
-		var err error
-
-		entries, err = database.GetAllEntries()
-		if err != nil {
-			fmt.Printf("%s %+v\n", CharError, err)
-			os.Exit(1)
-		}
+		expandProjectFilter()
 
+		var err error
 		var sinceTime time.Time
 		var untilTime time.Time
 
@@ -103,7 +97,16 @@ var exportCmd = &cobra.Command{
 		}
 
 		var filteredEntries []Entry
-		filteredEntries, err = GetFilteredEntries(entries, project, task, sinceTime, untilTime)
+		query := EntryQuery{
+			Project: project,
+			Task:    task,
+			Since:   sinceTime,
+			Until:   untilTime,
+		}
+		if exportUnbilled {
+			query.Billing = BillingUnbilled
+		}
+		filteredEntries, err = database.QueryEntries(query)
 		if err != nil {
 			fmt.Printf("%s %+v\n", CharError, err)
 			os.Exit(1)
@@ -156,12 +159,15 @@ func init() {
 	exportCmd.Flags().StringVar(&format, "format", "zeit", "Format to export, possible values: zeit, csv")
 	exportCmd.Flags().StringVar(&since, "since", "", "Date/time to start the export from")
 	exportCmd.Flags().StringVar(&until, "until", "", "Date/time to export until")
-	exportCmd.Flags().StringVarP(&project, "project", "p", "", "Project to be exported")
+	exportCmd.Flags().StringVarP(&project, "project", "p", "", "Project to be exported, or an @alias")
 	exportCmd.Flags().StringVarP(&task, "task", "t", "", "Task to be exported")
 	exportCmd.Flags().BoolVar(&exportDate, "date", true, "Set to true, if you want to export the 'Date' aswell")
 	exportCmd.Flags().BoolVar(&exportHours, "hours-decimal", true, "Set to true if you want Hours to be exported too")
 	exportCmd.Flags().StringVar(&fileName, "file-name", "", "Set the output file for the csv export")
 	exportCmd.Flags().BoolVar(&exportAllFields, "export-all-fields", false, "Set to true if you want to export all the available fields to the csv")
+	exportCmd.Flags().BoolVar(&exportUnbilled, "unbilled", false, "Only export activities that are neither invoiced nor written off")
+	registerProjectTaskCompletion(exportCmd, true)
+
 	var err error
 	database, err = InitDB()
 	if err != nil {
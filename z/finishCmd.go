@@ -1,55 +1,166 @@
 package z
 
 import (
-	"database/sql"
-	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
+
+	"github.com/gookit/color"
 
 	"github.com/spf13/cobra"
 )
 
+var finishCap bool
+var finishAttachCommits bool
+
 var finishCmd = &cobra.Command{
-	Use:   "finish",
+	Use:   "finish ([flags]) [id]",
 	Short: "Finish currently running activity",
-	Long:  "Finishing tracking of currently running activity.",
+	Long:  "Finishing tracking of currently running activity.\n\nWith parallel timers running, pick the one to finish by its id or with --project/--task.",
+	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 
-		runningEntry, err := database.GetRunningEntry()
-		if err != nil {
-			switch {
-			case errors.Is(err, sql.ErrNoRows):
-				fmt.Printf("%s no task is currently running. Can only finish a running task.\n", CharFinish)
-				os.Exit(1)
-			default:
-				fmt.Printf("%s %+v\n", CharError, err)
-				os.Exit(1)
-			}
-		}
-		// Redudant but I keep it for now.
+		repairRunningEntries()
+
+		var err error
+		runningEntry := selectRunningEntry(args)
 		if runningEntry == nil {
 			fmt.Printf("%s no task is currently running. Can only finish a running task.\n", CharFinish)
 			os.Exit(1)
 		}
 		// Finishing the entry
-		runningEntry.SetFinish()
+		if finish == "" {
+			runningEntry.SetFinish()
+			if runningEntry.IsForgotten(maxRunningDuration()) {
+				runningEntry.Finish = capForgottenEntry(*runningEntry)
+			}
+		} else {
+			_, err = runningEntry.SetFinishFromString(finish)
+			if err != nil {
+				fmt.Printf("%s could not parse --finish '%s'. Error: %s\n", CharError, finish, err.Error())
+				os.Exit(1)
+			}
+			runningEntry.Running = false
+		}
+		if !runningEntry.Begin.Before(runningEntry.Finish) {
+			fmt.Printf("%s --finish has to be after the task began at %s.\n", CharError, runningEntry.Begin.Format("2006-01-02 15:04"))
+			os.Exit(1)
+		}
+		runningEntry.Hours = runningEntry.GetDuration()
 		if notes != "" {
 			runningEntry.Notes = strings.ReplaceAll(notes, "\\n", "\n")
 		}
+		if finishAttachCommits {
+			commits, err := GetCommits(*runningEntry)
+			if err != nil {
+				fmt.Printf("%s %+v\n", CharError, err)
+				os.Exit(1)
+			}
+			if len(commits) > 0 {
+				runningEntry.Notes = strings.TrimSpace(runningEntry.Notes + "\n" + GetNotesForCommits(commits))
+			}
+		}
 		err = database.AddFinishToEntry(*runningEntry)
 		if err != nil {
 			fmt.Printf("%s something ent wrong updating the entry. Error: %s", CharError, err.Error())
 			os.Exit(1)
 		}
 		fmt.Println(runningEntry.GetOutputForFinish())
+		warnBudget(*runningEntry)
 	},
 }
 
+// capForgottenEntry warns about an entry that ran longer than it should have
+// and returns when it should be finished: now, the suggested cap or a time typed in.
+func capForgottenEntry(entry Entry) time.Time {
+	max := maxRunningDuration()
+	suggested := entry.SuggestedCap(max)
+	fmt.Printf("%s %s on %s has been running for %sh, longer than the maximum of %sh.\n", CharError,
+		color.FgLightWhite.Render(entry.Task),
+		color.FgLightWhite.Render(entry.Project),
+		color.FgLightWhite.Render(fmtDuration(entry.ActiveDuration())),
+		fmtDuration(max))
+	if finishCap {
+		return suggested
+	}
+	answer := ask(fmt.Sprintf("Finish it at %s instead? Type a time like 17:30 to finish it then. [y/N/time]", suggested.Format("2006-01-02 15:04")))
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return suggested
+	case "", "n", "no":
+		return time.Now().Truncate(0)
+	}
+	switch GetTimeFormat(answer) {
+	case TFAbsTwelveHour, TFAbsTwentyfourHour:
+		capTime, err := ParseTime(answer)
+		if err == nil {
+			// The time is meant on the day the entry began, not today.
+			return time.Date(entry.Begin.Year(), entry.Begin.Month(), entry.Begin.Day(), capTime.Hour(), capTime.Minute(), 0, 0, entry.Begin.Location())
+		}
+	}
+	fmt.Printf("%s could not parse '%s', nothing was changed.\n", CharError, answer)
+	os.Exit(1)
+	return time.Time{}
+}
+
+// repairRunningEntries handles databases from before only a single entry could be running.
+// The latest entry stays running, every other one is finished when the next one began.
+func repairRunningEntries() {
+	allRunningEntries, err := database.GetRunningEntries()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+	// Parallel entries are meant to run next to the others.
+	var runningEntries []Entry
+	for _, entry := range allRunningEntries {
+		if !entry.Parallel {
+			runningEntries = append(runningEntries, entry)
+		}
+	}
+	if len(runningEntries) < 2 {
+		return
+	}
+	fmt.Printf("%s %d tasks are running at the same time, only one should be:\n", CharError, len(runningEntries))
+	for _, entry := range runningEntries {
+		fmt.Printf("%s\n", entry.GetOutput(false))
+	}
+	if !confirm("Keep only the latest one running and finish every other one when the next one began?") {
+		fmt.Printf("%s nothing was changed.\n", CharInfo)
+		os.Exit(1)
+	}
+	for i, entry := range runningEntries[:len(runningEntries)-1] {
+		entry.Finish = runningEntries[i+1].Begin
+		entry.Running = false
+		entry.Hours = entry.GetDuration()
+		overlapping, err := database.UpdateEntry(entry)
+		if err != nil {
+			fmt.Printf("%s could not finish entry %d. Error: %s\n", CharError, entry.ID, err.Error())
+			os.Exit(1)
+		}
+		warnOverlaps(entry, overlapping)
+		fmt.Print(entry.GetOutputForFinish())
+	}
+	err = database.EnsureSingleRunningIndex()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(finishCmd)
-	finishCmd.Flags().StringVarP(&finish, "finish", "s", "", "Time the activity should finish at\n\nEither in the formats 16:00 / 4:00PM \nor relative to the current time, \ne.g. -0:15 (now minus 15 minutes), +1.50 (now plus 1:30h).\nMust be after --begin time.")
+	finishCmd.Flags().StringVarP(&finish, "finish", "s", "", "Time the activity should finish at\n\nEither in the formats 16:00 / 4:00PM \nor relative to the current time, \ne.g. -0:15 (now minus 15 minutes), +1.50 (now plus 1:30h).\nMust be after the time the activity began.")
 	finishCmd.Flags().StringVarP(&notes, "notes", "n", "", "Add notes to the task while finishing it.")
+	finishCmd.Flags().StringVarP(&project, "project", "p", "", "Finish the running activity of this project")
+	finishCmd.Flags().StringVarP(&task, "task", "t", "", "Finish the running activity with this task")
+	finishCmd.Flags().BoolVar(&finishCap, "cap", false, "Finish a forgotten activity at the end of the working day it began on (ZEIT_WORKDAY_END) without asking")
+	finishCmd.Flags().BoolVar(&finishAttachCommits, "attach-commits", false, "Append the commits made to the project's repositories during the activity to its notes")
+	finishCmd.Flags().DurationVar(&maxRunning, "max-running", 0, "Running activities longer than this count as forgotten (default ZEIT_MAX_RUNNING or 12h)")
+
+	registerProjectTaskCompletion(finishCmd, false)
+	finishCmd.ValidArgsFunction = completeRunningEntryID
 
 	var err error
 	database, err = InitDB()
@@ -0,0 +1,138 @@
+package z
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+)
+
+// ProjectRepo maps a project to a local git repository its work is committed to.
+type ProjectRepo struct {
+	Project string
+	Path    string
+}
+
+// Commit is a git commit made while an entry was tracked.
+type Commit struct {
+	Repo    string
+	Hash    string
+	Subject string
+}
+
+func (commit Commit) ShortHash() string {
+	if len(commit.Hash) > 7 {
+		return commit.Hash[:7]
+	}
+	return commit.Hash
+}
+
+func (db *Database) AddProjectRepo(repo ProjectRepo) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err := db.DB.ExecContext(ctx, `INSERT OR IGNORE INTO project_repos(project, project_key, path) VALUES(?, ?, ?);`,
+		repo.Project, GetIdFromName(repo.Project), repo.Path)
+	return err
+}
+
+// GetProjectRepos returns the repositories of the project, of all projects if it is empty.
+// The project is matched like GetIdFromName does, so 'acme' finds the repositories of 'Acme'.
+func (db *Database) GetProjectRepos(project string) ([]ProjectRepo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	rows, err := db.DB.QueryContext(ctx, `SELECT project, path FROM project_repos WHERE ? = '' OR project_key = ? ORDER BY project, path;`,
+		project, GetIdFromName(project))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var repos []ProjectRepo
+	for rows.Next() {
+		var repo ProjectRepo
+		err := rows.Scan(&repo.Project, &repo.Path)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, repo)
+	}
+	return repos, rows.Err()
+}
+
+// DeleteProjectRepo removes the mapping, sql.ErrNoRows if there is none.
+func (db *Database) DeleteProjectRepo(repo ProjectRepo) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	result, err := db.DB.ExecContext(ctx, `DELETE FROM project_repos WHERE project_key = ? AND path = ?;`, GetIdFromName(repo.Project), repo.Path)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ProjectOfRepo returns the project repo is mapped to, empty if there is none or more than one.
+func ProjectOfRepo(repo string) (string, error) {
+	repos, err := database.GetProjectRepos("")
+	if err != nil {
+		return "", err
+	}
+	var projects []string
+	for _, projectRepo := range repos {
+		if projectRepo.Path == repo {
+			projects = append(projects, projectRepo.Project)
+		}
+	}
+	if len(projects) != 1 {
+		return "", nil
+	}
+	return projects[0], nil
+}
+
+// GetCommits returns the commits made to the repositories of the entry's project while it was tracked.
+func GetCommits(entry Entry) ([]Commit, error) {
+	repos, err := database.GetProjectRepos(entry.Project)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, repo := range repos {
+		log, _, err := GetGitLog(repo.Path, entry.Begin, entry.end())
+		if err != nil {
+			return nil, fmt.Errorf("could not read the commits of %s: %w", repo.Path, err)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(log), "\n") {
+			if line == "" {
+				continue
+			}
+			hash, subject, _ := strings.Cut(line, " ")
+			commits = append(commits, Commit{Repo: repo.Path, Hash: hash, Subject: subject})
+		}
+	}
+	return commits, nil
+}
+
+func GetOutputForCommits(commits []Commit) string {
+	var output = ""
+	for _, commit := range commits {
+		output = fmt.Sprintf("%s   %s %s\n", output, color.FgLightYellow.Render(commit.ShortHash()), commit.Subject)
+	}
+	return output
+}
+
+// GetNotesForCommits is how commits are appended to the notes of an entry.
+func GetNotesForCommits(commits []Commit) string {
+	lines := []string{"Commits:"}
+	for _, commit := range commits {
+		lines = append(lines, fmt.Sprintf("- %s %s", commit.ShortHash(), commit.Subject))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,79 @@
+package z
+
+import (
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetGitLog(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := filepath.Join(t.TempDir(), "repo")
+	gitOrFail := func(args ...string) {
+		t.Helper()
+		_, err := git(filepath.Dir(repo), append([]string{"-C", repo}, args...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := git(filepath.Dir(repo), "init", "-q", repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Dots, brackets and '+' would be a pattern if the name was not escaped.
+	gitOrFail("config", "user.name", "A.B (Dev)+")
+	gitOrFail("config", "user.email", "ab@example.com")
+	gitOrFail("commit", "-q", "--allow-empty", "-m", "on the default branch")
+	gitOrFail("checkout", "-q", "-b", "feature")
+	gitOrFail("commit", "-q", "--allow-empty", "-m", "on another branch")
+	gitOrFail("-c", "user.name=AxB (Dev)", "commit", "-q", "--allow-empty", "-m", "by someone else")
+	gitOrFail("-c", "user.name=A.B (Dev)+ Junior", "commit", "-q", "--allow-empty", "-m", "by someone with a longer name")
+	gitOrFail("checkout", "-q", "-")
+
+	log, _, err := GetGitLog(repo, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var subjects []string
+	for _, line := range strings.Split(strings.TrimSpace(log), "\n") {
+		_, subject, _ := strings.Cut(line, " ")
+		subjects = append(subjects, subject)
+	}
+	// The commits are made within the same second, so git may list them in any order.
+	sort.Strings(subjects)
+	got := strings.Join(subjects, ", ")
+	if want := "on another branch, on the default branch"; got != want {
+		t.Errorf("GetGitLog found the commits %q, want %q", got, want)
+	}
+}
+
+func TestProjectReposMatchNormalizedProject(t *testing.T) {
+	db := newTestDatabase(t)
+	err := db.AddProjectRepo(ProjectRepo{Project: "Acme", Path: "/src/acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repos, err := db.GetProjectRepos("acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 1 || repos[0].Project != "Acme" || repos[0].Path != "/src/acme" {
+		t.Fatalf("repositories of 'acme' are %+v, want the one of 'Acme'", repos)
+	}
+	err = db.DeleteProjectRepo(ProjectRepo{Project: "ACME", Path: "/src/acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repos, err = db.GetProjectRepos("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 0 {
+		t.Errorf("repositories left after removing the mapping: %+v", repos)
+	}
+}
@@ -3,6 +3,7 @@ package z
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"math"
 	"os/exec"
 	"regexp"
@@ -126,6 +127,8 @@ func GetISOWeekInMonth(date time.Time) (month int, weeknumber int) {
 	return int(changedDate.Month()), int(math.Ceil(float64(changedDate.Day()) / 7.0))
 }
 
+// GetGitLog returns the commits the configured git user made in repo between since and until, one per line as '<hash> <subject>'.
+// Commits on every branch count, stashes do not.
 func GetGitLog(repo string, since time.Time, until time.Time) (string, string, error) {
 	var stdout, stderr bytes.Buffer
 	cmd := exec.Command("git", "-C", repo, "config", "user.name")
@@ -133,9 +136,13 @@ func GetGitLog(repo string, since time.Time, until time.Time) (string, string, e
 	cmd.Stderr = &stderr
 	err := cmd.Run()
 	if err != nil {
-		return "", "", err
+		if stderr.Len() == 0 {
+			return "", "", fmt.Errorf("no git user.name is configured for %s", repo)
+		}
+		return "", stderr.String(), errors.New(strings.TrimSpace(stderr.String()))
 	}
-	gitUserStr, gitUserErrStr := stdout.String(), stderr.String()
+	// The name ends with a newline, which would not match any author.
+	gitUserStr, gitUserErrStr := strings.TrimSpace(stdout.String()), stderr.String()
 	if gitUserStr == "" && gitUserErrStr != "" {
 		return gitUserStr, gitUserErrStr, errors.New(gitUserErrStr)
 	}
@@ -143,11 +150,16 @@ func GetGitLog(repo string, since time.Time, until time.Time) (string, string, e
 	stdout.Reset()
 	stderr.Reset()
 
-	cmd = exec.Command("git", "-C", repo, "log", "--author", gitUserStr, "--since", since.Format("2006-01-02T15:04:05-0700"), "--until", until.Format("2006-01-02T15:04:05-0700"), "--pretty=oneline")
+	// --author is a pattern, the name has to match as it is and in full.
+	author := "^" + regexp.QuoteMeta(gitUserStr) + " <"
+	cmd = exec.Command("git", "-C", repo, "log", "--exclude=refs/stash", "--all", "--extended-regexp", "--author", author, "--since", since.Format("2006-01-02T15:04:05-0700"), "--until", until.Format("2006-01-02T15:04:05-0700"), "--pretty=oneline")
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	err = cmd.Run()
 	if err != nil {
+		if stderr.Len() > 0 {
+			return "", stderr.String(), errors.New(strings.TrimSpace(stderr.String()))
+		}
 		return "", "", err
 	}
 
@@ -0,0 +1,173 @@
+package z
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// gitHooks are the hooks 'zeit hooks install' writes.
+var gitHooks = []string{"post-checkout", "post-commit"}
+
+const (
+	// hookMarker tells the hooks written by zeit from any others.
+	hookMarker = "# installed by zeit"
+	// hookBackupSuffix is appended to a hook that was in place before zeit installed its own.
+	hookBackupSuffix = ".zeit-backup"
+)
+
+// TaskFromBranch derives a task from a branch name, false if the pattern does not match.
+// The first group of the pattern is taken as it is, the following ones with '-' and '_' as spaces,
+// e.g. 'feature/PROJ-123-login' becomes 'PROJ-123 login'. A pattern without groups takes the whole match.
+func TaskFromBranch(pattern *regexp.Regexp, branch string) (string, bool) {
+	match := pattern.FindStringSubmatch(branch)
+	if match == nil {
+		return "", false
+	}
+	if len(match) == 1 {
+		return strings.TrimSpace(match[0]), match[0] != ""
+	}
+	separators := strings.NewReplacer("-", " ", "_", " ")
+	parts := []string{}
+	for i, group := range match[1:] {
+		if i > 0 {
+			group = strings.Join(strings.Fields(separators.Replace(group)), " ")
+		}
+		if group != "" {
+			parts = append(parts, group)
+		}
+	}
+	return strings.Join(parts, " "), len(parts) > 0
+}
+
+// git runs a git command in repo and returns its trimmed output, the error is what git printed.
+func git(repo string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		if stderr.Len() > 0 {
+			return "", errors.New(strings.TrimSpace(stderr.String()))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// GitRepoRoot returns the top level directory of the repository path lies in.
+func GitRepoRoot(path string) (string, error) {
+	return git(path, "rev-parse", "--show-toplevel")
+}
+
+// GitBranch returns the branch checked out in repo, false for a detached HEAD.
+func GitBranch(repo string) (string, bool, error) {
+	branch, err := git(repo, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", false, err
+	}
+	return branch, branch != "HEAD", nil
+}
+
+// gitHooksDir returns the directory git runs the hooks of repo from, it respects core.hooksPath.
+func gitHooksDir(repo string) (string, error) {
+	dir, err := git(repo, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repo, dir)
+	}
+	return dir, nil
+}
+
+// shellQuote quotes value for a POSIX shell.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// hookScript is the hook calling 'zeit hooks run', after the hook that was in place before, if any.
+func hookScript(executable string, hook string, pattern string) string {
+	command := fmt.Sprintf("%s hooks run %s", shellQuote(executable), hook)
+	if pattern != "" {
+		command = fmt.Sprintf("%s --branch-pattern %s", command, shellQuote(pattern))
+	}
+	return fmt.Sprintf(`#!/bin/sh
+%s, 'zeit hooks uninstall' restores the hook that was here before.
+status=0
+if [ -x "$0%s" ]; then
+	"$0%s" "$@" || status=$?
+fi
+%s "$@"
+exit $status
+`, hookMarker, hookBackupSuffix, hookBackupSuffix, command)
+}
+
+func isZeitHook(path string) bool {
+	content, err := os.ReadFile(path)
+	return err == nil && bytes.Contains(content, []byte(hookMarker))
+}
+
+// InstallHooks writes the zeit hooks to repo. Hooks that are there already are kept as a backup and still run.
+func InstallHooks(repo string, executable string, pattern string) error {
+	dir, err := gitHooksDir(repo)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	for _, hook := range gitHooks {
+		path := filepath.Join(dir, hook)
+		_, err := os.Stat(path)
+		if err == nil && !isZeitHook(path) {
+			if _, err := os.Stat(path + hookBackupSuffix); err == nil {
+				return fmt.Errorf("can not back up %s, %s exists already", path, path+hookBackupSuffix)
+			}
+			err = os.Rename(path, path+hookBackupSuffix)
+			if err != nil {
+				return err
+			}
+		}
+		err = os.WriteFile(path, []byte(hookScript(executable, hook, pattern)), 0755)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UninstallHooks removes the zeit hooks from repo and puts back the hooks they replaced.
+// Hooks that were changed to no longer call zeit are left alone.
+func UninstallHooks(repo string) (int, error) {
+	dir, err := gitHooksDir(repo)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, hook := range gitHooks {
+		path := filepath.Join(dir, hook)
+		if !isZeitHook(path) {
+			continue
+		}
+		err = os.Remove(path)
+		if err != nil {
+			return removed, err
+		}
+		removed++
+		if _, err := os.Stat(path + hookBackupSuffix); err == nil {
+			err = os.Rename(path+hookBackupSuffix, path)
+			if err != nil {
+				return removed, err
+			}
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,202 @@
+package z
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage git hooks that switch activities with the branch",
+	Long: `Manage git hooks that switch to the task of the branch you are working on.
+
+After checking out a branch, zeit switches to a task derived from its name,
+e.g. 'feature/PROJ-123-login' becomes 'PROJ-123 login', on the project the
+repository is mapped to (see 'zeit repo'). After a commit, a running activity
+is switched the same way, but no activity is started.
+
+The name is matched against --branch-pattern, ZEIT_BRANCH_PATTERN or a default
+pattern for ticket branches. Its first group is taken as it is, the following
+ones with '-' and '_' as spaces. Branches that do not match switch nothing.`,
+}
+
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install [repo]",
+	Short: "Install the git hooks into a repository",
+	Long:  "Install the post-checkout and post-commit hooks into a repository. Hooks that are there already keep running before the ones of zeit.",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		repo := hooksRepo(args)
+		if branchPattern != "" {
+			_, err := branchRegexp()
+			if err != nil {
+				fmt.Printf("%s could not parse --branch-pattern '%s'. Error: %s\n", CharError, branchPattern, err.Error())
+				os.Exit(1)
+			}
+		}
+		if project != "" {
+			err := database.AddProjectRepo(ProjectRepo{Project: project, Path: repo})
+			if err != nil {
+				fmt.Printf("%s %+v\n", CharError, err)
+				os.Exit(1)
+			}
+		}
+		executable, err := os.Executable()
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		err = InstallHooks(repo, executable, branchPattern)
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s installed the git hooks into %s\n", CharInfo, color.FgLightWhite.Render(repo))
+		if repoProject(repo) == "" {
+			fmt.Printf("%s %s is not mapped to a project, the project of the running activity will be kept.\nMap it with 'zeit repo add -p <project> %s'\n", CharInfo, repo, repo)
+		}
+	},
+}
+
+var hooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall [repo]",
+	Short: "Remove the git hooks from a repository",
+	Long:  "Remove the hooks of zeit from a repository and restore the ones that were there before.",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		repo := hooksRepo(args)
+		removed, err := UninstallHooks(repo)
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		if removed == 0 {
+			fmt.Printf("%s there are no git hooks of zeit in %s\n", CharInfo, repo)
+			return
+		}
+		fmt.Printf("%s removed the git hooks from %s\n", CharErase, color.FgLightWhite.Render(repo))
+	},
+}
+
+var hooksRunCmd = &cobra.Command{
+	Use:    "run [hook] ([args])",
+	Short:  "Run a git hook",
+	Long:   "Run by the git hooks zeit installed, with the arguments git passes to them.",
+	Hidden: true,
+	Args:   cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		hook := args[0]
+		// post-checkout gets the previous HEAD, the new HEAD and 1 if a branch was checked out, 0 for files.
+		if hook == "post-checkout" && (len(args) < 4 || args[3] != "1") {
+			return
+		}
+		repo, err := GitRepoRoot(".")
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		branch, ok, err := GitBranch(repo)
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		if !ok {
+			return
+		}
+		pattern, err := branchRegexp()
+		if err != nil {
+			fmt.Printf("%s could not parse the branch pattern. Error: %s\n", CharError, err.Error())
+			os.Exit(1)
+		}
+		branchTask, ok := TaskFromBranch(pattern, branch)
+		if !ok {
+			return
+		}
+
+		var runningEntry *Entry
+		runningEntries, err := database.GetRunningEntries()
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		// Parallel timers run next to the switched one and are left alone.
+		for i := range runningEntries {
+			if !runningEntries[i].Parallel {
+				runningEntry = &runningEntries[i]
+			}
+		}
+		if runningEntry == nil && hook != "post-checkout" {
+			return
+		}
+
+		branchProject := repoProject(repo)
+		if branchProject == "" && runningEntry != nil {
+			branchProject = runningEntry.Project
+		}
+		if branchProject == "" {
+			fmt.Printf("%s zeit can not track %s, %s is not mapped to a project.\nMap it with 'zeit repo add -p <project> %s'\n", CharError, branchTask, repo, repo)
+			os.Exit(1)
+		}
+		if runningEntry != nil && runningEntry.Project == branchProject && runningEntry.Task == branchTask {
+			return
+		}
+
+		newEntry := NewEntry(branchProject, branchTask)
+		newEntry.Begin = time.Now().Truncate(0)
+		newEntry.SetDateFromBegining()
+		switchTo(runningEntry, &newEntry)
+	},
+}
+
+// hooksRepo returns the top level directory of the repository given in args, or of the current one.
+func hooksRepo(args []string) string {
+	path := "."
+	if len(args) > 0 {
+		path = args[0]
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+	repo, err := GitRepoRoot(path)
+	if err != nil {
+		fmt.Printf("%s %s is no git repository.\n", CharError, path)
+		os.Exit(1)
+	}
+	return repo
+}
+
+// repoProject returns the project repo is mapped to, empty if none or more than one.
+func repoProject(repo string) string {
+	repoProject, err := ProjectOfRepo(repo)
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+	return repoProject
+}
+
+func init() {
+	rootCmd.AddCommand(hooksCmd)
+	hooksCmd.AddCommand(hooksInstallCmd)
+	hooksCmd.AddCommand(hooksUninstallCmd)
+	hooksCmd.AddCommand(hooksRunCmd)
+	hooksInstallCmd.Flags().StringVarP(&project, "project", "p", "", "Map the repository to this project")
+	hooksInstallCmd.Flags().StringVar(&branchPattern, "branch-pattern", "", "Regular expression deriving the task from a branch name (default ZEIT_BRANCH_PATTERN or ticket branches like feature/PROJ-123-login)")
+	hooksRunCmd.Flags().StringVar(&branchPattern, "branch-pattern", "", "Regular expression deriving the task from a branch name")
+
+	registerProjectTaskCompletion(hooksInstallCmd, false)
+
+	var err error
+	database, err = InitDB()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,117 @@
+package z
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestTaskFromBranch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		branch  string
+		task    string
+		ok      bool
+	}{
+		{defaultBranchPattern, "feature/PROJ-123-login", "PROJ-123 login", true},
+		{defaultBranchPattern, "bugfix/PROJ-7_fix_the--build", "PROJ-7 fix the build", true},
+		{defaultBranchPattern, "ABC-1", "ABC-1", true},
+		{defaultBranchPattern, "main", "", false},
+		{defaultBranchPattern, "feature/login", "", false},
+		{`^feature/(.+)$`, "feature/new-login", "new-login", true},
+		{`^issue-[0-9]+$`, "issue-42", "issue-42", true},
+	}
+	for _, test := range tests {
+		task, ok := TaskFromBranch(regexp.MustCompile(test.pattern), test.branch)
+		if task != test.task || ok != test.ok {
+			t.Errorf("TaskFromBranch(%q, %q) = %q, %t, want %q, %t", test.pattern, test.branch, task, ok, test.task, test.ok)
+		}
+	}
+}
+
+func TestInstallAndUninstallHooks(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	repo := filepath.Join(dir, "repo")
+	log := filepath.Join(dir, "log")
+	_, err := git(dir, "init", "-q", repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A fake zeit that only logs how it was called.
+	executable := filepath.Join(dir, "zeit")
+	writeScript(t, executable, "#!/bin/sh\necho \"zeit $*\" >> "+shellQuote(log)+"\n")
+	hooksDir, err := gitHooksDir(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	postCommit := filepath.Join(hooksDir, "post-commit")
+	existingHook := "#!/bin/sh\necho \"existing $*\" >> " + shellQuote(log) + "\n"
+	writeScript(t, postCommit, existingHook)
+
+	for i := 0; i < 2; i++ {
+		// Installing again must not back up the hook of zeit.
+		err = InstallHooks(repo, executable, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if backup := readFile(t, postCommit+hookBackupSuffix); backup != existingHook {
+		t.Errorf("backup of the existing hook is\n%s\nwant\n%s", backup, existingHook)
+	}
+	if _, err := os.Stat(filepath.Join(hooksDir, "post-checkout"+hookBackupSuffix)); !os.IsNotExist(err) {
+		t.Errorf("post-checkout was backed up, though there was none before")
+	}
+
+	// The hook that was there before still runs, ahead of zeit.
+	err = exec.Command(postCommit, "a", "b").Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readFile(t, log), "existing a b\nzeit hooks run post-commit a b\n"; got != want {
+		t.Errorf("running the hook logged\n%s\nwant\n%s", got, want)
+	}
+
+	removed, err := UninstallHooks(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != len(gitHooks) {
+		t.Errorf("removed %d hooks, want %d", removed, len(gitHooks))
+	}
+	if restored := readFile(t, postCommit); restored != existingHook {
+		t.Errorf("restored hook is\n%s\nwant\n%s", restored, existingHook)
+	}
+	if _, err := os.Stat(postCommit + hookBackupSuffix); !os.IsNotExist(err) {
+		t.Errorf("the backup is still there after uninstalling")
+	}
+	if _, err := os.Stat(filepath.Join(hooksDir, "post-checkout")); !os.IsNotExist(err) {
+		t.Errorf("post-checkout is still there after uninstalling")
+	}
+}
+
+func writeScript(t *testing.T, path string, content string) {
+	t.Helper()
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte(content), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
@@ -3,6 +3,7 @@ package z
 import (
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 
@@ -29,6 +30,13 @@ For the 'csv' output, please make sure you export all the fields.`,
 			fmt.Printf("%s Please specify an import file.\n", CharError)
 			os.Exit(1)
 		}
+		// Entries overlapping others are only listed with --verbose, otherwise they are counted.
+		overlapped := 0
+		invoices, err := database.GetInvoices()
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
 		switch format {
 		case "zeit":
 			fileContent, err := os.ReadFile(importFile)
@@ -51,16 +59,27 @@ For the 'csv' output, please make sure you export all the fields.`,
 					fmt.Printf("%s Could not convert entryDB '%+v' to entry. Error: %s\n", CharError, v, err.Error())
 					os.Exit(1)
 				}
-				err = database.AddEntry(entryConv, false)
+				importBilling(entryConv, invoices)
+				overlapping, err := database.AddEntry(entryConv, false)
+				var overlapErr *OverlapError
+				if errors.As(err, &overlapErr) {
+					fmt.Printf("%s skipped: %s\n", CharError, overlapErr.Error())
+					continue
+				}
 				if err != nil {
 					fmt.Printf("%s Could not add entry '%+v' to the database. Error: %s\n", CharError, entryConv, err.Error())
 					os.Exit(1)
 				}
+				if len(overlapping) > 0 {
+					overlapped++
+				}
 				if verbose {
+					warnOverlaps(*entryConv, overlapping)
 					fmt.Printf("%s added Entry: '%s' to the database\n", CharInfo, entryConv.GetOutputStrShort())
 				}
 			}
 			fmt.Printf("%s added all entries to the database\n", CharInfo)
+			warnImportOverlaps(overlapped)
 
 		case "csv":
 			file, err := os.Open(importFile)
@@ -98,17 +117,28 @@ For the 'csv' output, please make sure you export all the fields.`,
 					fmt.Printf("%s Could not convert entryDB '%+v' to entry. Error: %s\n", CharError, eDB, err.Error())
 					os.Exit(1)
 				}
-				err = database.AddEntry(entryConv, false)
+				importBilling(entryConv, invoices)
+				overlapping, err := database.AddEntry(entryConv, false)
+				var overlapErr *OverlapError
+				if errors.As(err, &overlapErr) {
+					fmt.Printf("%s skipped: %s\n", CharError, overlapErr.Error())
+					continue
+				}
 				if err != nil {
 					fmt.Printf("%s Could not add entry '%+v' to the database. Error: %s\n", CharError, entryConv, err.Error())
 					os.Exit(1)
 				}
+				if len(overlapping) > 0 {
+					overlapped++
+				}
 				if verbose {
+					warnOverlaps(*entryConv, overlapping)
 					fmt.Printf("%s added Entry: '%s' to the database\n", CharInfo, entryConv.GetOutputStrShort())
 				}
 
 			}
 			fmt.Printf("%s added all entries to the database\n", CharInfo)
+			warnImportOverlaps(overlapped)
 
 		default:
 			fmt.Printf("%s Could not find an approved format. Please try again\n", CharError)
@@ -117,9 +147,18 @@ For the 'csv' output, please make sure you export all the fields.`,
 	},
 }
 
+// warnImportOverlaps points to 'zeit overlaps' if imported entries were saved over others.
+func warnImportOverlaps(overlapped int) {
+	if overlapped == 0 {
+		return
+	}
+	fmt.Printf("%s %d imported entries overlap others, type 'zeit overlaps' to resolve this.\n", CharError, overlapped)
+}
+
 func init() {
 	rootCmd.AddCommand(importCmd)
 	importCmd.Flags().StringVar(&format, "format", "", "Format to import, possible values: zeit, csv")
+	importCmd.Flags().StringVar(&overlapPolicy, "overlap", "", "What to do with entries overlapping existing ones: warn, reject (skip them) or ignore. Default: ZEIT_OVERLAP or warn")
 	importCmd.Flags().BoolVar(&verbose, "verbose", false, "Show output for each added entry. Default: false")
 
 	var err error
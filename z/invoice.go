@@ -0,0 +1,304 @@
+package z
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/shopspring/decimal"
+)
+
+// How the lines of an invoice are grouped, set via 'zeit invoice --group-by'.
+const (
+	InvoiceGroupByTask = "task"
+	InvoiceGroupByDay  = "day"
+)
+
+// Formats an invoice is written in, set via 'zeit invoice --format'.
+const (
+	InvoiceFormatMarkdown = "md"
+	InvoiceFormatHTML     = "html"
+)
+
+var ErrInvoiceExists = errors.New("an invoice with this number exists already")
+
+// InvoiceLine is a line item, the hours of a task or of a day at one rate.
+type InvoiceLine struct {
+	Label   string
+	Details string
+	Hours   decimal.Decimal
+	Rate    decimal.Decimal
+	Amount  decimal.Decimal
+}
+
+type Invoice struct {
+	// Number is assigned when the invoice is added, unless it is given.
+	Number    string
+	Issued    time.Time
+	Project   string
+	Since     time.Time
+	Until     time.Time
+	GroupBy   string
+	Currency  string
+	TaxRate   decimal.Decimal // in percent
+	Sender    string
+	Recipient string
+	Lines     []InvoiceLine
+	Entries   []Entry
+}
+
+// NewInvoice groups the entries into line items at their rates. Hours are rounded to
+// hundredths per line, amounts to cents. It fails if a task of the entries has no rate.
+func NewInvoice(project string, entries []Entry, rates Rates, groupBy string) (*Invoice, error) {
+	invoice := &Invoice{Project: project, Issued: time.Now(), GroupBy: groupBy, TaxRate: decimal.Zero, Entries: entries}
+	lines := map[string]*InvoiceLine{}
+	var order []string
+	var unrated []string
+	for _, entry := range entries {
+		rate, ok := rates.For(entry.Task)
+		if !ok {
+			if !containsString(unrated, entry.Task) {
+				unrated = append(unrated, entry.Task)
+			}
+			continue
+		}
+		var key, label string
+		switch groupBy {
+		case InvoiceGroupByTask:
+			key, label = GetIdFromName(entry.Task), entry.Task
+		case InvoiceGroupByDay:
+			label = entry.Begin.Format(dayFormat)
+			// Tasks at different rates on the same day need a line each.
+			key = label + " " + rate.String()
+		default:
+			return nil, fmt.Errorf("unknown grouping '%s'", groupBy)
+		}
+		line, ok := lines[key]
+		if !ok {
+			line = &InvoiceLine{Label: label, Hours: decimal.Zero, Rate: rate}
+			lines[key] = line
+			order = append(order, key)
+		}
+		line.Hours = line.Hours.Add(entry.GetDuration())
+		if groupBy == InvoiceGroupByDay && !strings.Contains(", "+line.Details+", ", ", "+entry.Task+", ") {
+			line.Details = strings.TrimPrefix(line.Details+", "+entry.Task, ", ")
+		}
+	}
+	if len(unrated) > 0 {
+		return nil, fmt.Errorf("there is no rate for %s, set one with 'zeit rate set'", strings.Join(unrated, ", "))
+	}
+	for _, key := range order {
+		line := lines[key]
+		line.Hours = line.Hours.Round(2)
+		line.Amount = line.Hours.Mul(line.Rate).Round(2)
+		invoice.Lines = append(invoice.Lines, *line)
+	}
+	return invoice, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (invoice *Invoice) Hours() decimal.Decimal {
+	hours := decimal.Zero
+	for _, line := range invoice.Lines {
+		hours = hours.Add(line.Hours)
+	}
+	return hours
+}
+
+func (invoice *Invoice) Subtotal() decimal.Decimal {
+	subtotal := decimal.Zero
+	for _, line := range invoice.Lines {
+		subtotal = subtotal.Add(line.Amount)
+	}
+	return subtotal
+}
+
+func (invoice *Invoice) Tax() decimal.Decimal {
+	return invoice.Subtotal().Mul(invoice.TaxRate).Div(decimal.NewFromInt(100)).Round(2)
+}
+
+func (invoice *Invoice) Total() decimal.Decimal {
+	return invoice.Subtotal().Add(invoice.Tax())
+}
+
+// nextInvoiceNumber numbers the invoices of each year one after the other, like 'INV-2024-0007' with the prefix 'INV-'.
+// It follows the highest number of the year, so numbers given via --number are skipped.
+func nextInvoiceNumber(ctx context.Context, tx execer, prefix string, issued time.Time) (string, error) {
+	base := fmt.Sprintf("%s%d-", prefix, issued.Year())
+	rows, err := tx.QueryContext(ctx, `SELECT substr(number, ?) FROM invoices WHERE substr(number, 1, ?) = ?;`, len(base)+1, len(base), base)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	var highest int64
+	for rows.Next() {
+		var suffix string
+		err := rows.Scan(&suffix)
+		if err != nil {
+			return "", err
+		}
+		// Numbers like 'INV-2024-0007b' do not count.
+		number, err := strconv.ParseInt(suffix, 10, 64)
+		if err == nil && number > highest {
+			highest = number
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%04d", base, highest+1), nil
+}
+
+// AddInvoice records the invoice, assigns the next number to it if it has none and marks its entries as invoiced.
+func (db *Database) AddInvoice(invoice *Invoice) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if invoice.Number == "" {
+		invoice.Number, err = nextInvoiceNumber(ctx, tx, invoiceNumberPrefix(), invoice.Issued)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO invoices(number, project, issued, since, until, currency, total) VALUES(?, ?, ?, ?, ?, ?, ?);`,
+		invoice.Number, invoice.Project, invoice.Issued.Format(time.RFC3339), formatInvoiceDay(invoice.Since), formatInvoiceDay(invoice.Until),
+		invoice.Currency, invoice.Total().String())
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return ErrInvoiceExists
+	}
+	if err != nil {
+		return err
+	}
+	err = markInvoiced(ctx, tx, invoice.Number, invoice.Entries)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func formatInvoiceDay(day time.Time) string {
+	if day.IsZero() {
+		return ""
+	}
+	return day.Format(dayFormat)
+}
+
+func formatMoney(currency string, amount decimal.Decimal) string {
+	return strings.TrimSpace(amount.StringFixed(2) + " " + currency)
+}
+
+var invoiceFuncs = map[string]any{
+	"hours":   func(hours decimal.Decimal) string { return hours.StringFixed(2) },
+	"day":     formatInvoiceDay,
+	"lines":   func(text string) []string { return strings.Split(strings.TrimSpace(text), "\n") },
+	"money":   formatMoney,
+	"percent": func(percent decimal.Decimal) string { return percent.String() },
+	// cell keeps a '|' in a task from ending the cell of a Markdown table.
+	"cell": func(text string) string { return strings.ReplaceAll(text, "|", `\|`) },
+}
+
+// Lines end in two spaces, which is a line break in Markdown.
+const invoiceMarkdown = `# Invoice {{.Number}}
+{{if .Sender}}
+{{range lines .Sender}}{{.}}  
+{{end}}{{end}}{{if .Recipient}}
+**Bill to:**  
+{{range lines .Recipient}}{{.}}  
+{{end}}{{end}}
+Date: {{day .Issued}}  
+Project: {{.Project}}  
+{{if or (not .Since.IsZero) (not .Until.IsZero)}}Period: {{day .Since}} to {{day .Until}}  
+{{end}}
+| {{if eq .GroupBy "day"}}Day{{else}}Task{{end}} | Hours | Rate | Amount |
+|:---|---:|---:|---:|
+{{range .Lines}}| {{cell .Label}}{{if .Details}} ({{cell .Details}}){{end}} | {{hours .Hours}} | {{money $.Currency .Rate}} | {{money $.Currency .Amount}} |
+{{end}}
+{{if .TaxRate.IsPositive}}Subtotal: {{money .Currency .Subtotal}}  
+Tax ({{percent .TaxRate}}%): {{money .Currency .Tax}}  
+{{end}}**Total: {{money .Currency .Total}}** for {{hours .Hours}}h
+`
+
+const invoiceHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Number}}</title>
+<style>
+body { font-family: sans-serif; max-width: 50em; margin: 3em auto; color: #222; }
+.parties { display: flex; justify-content: space-between; margin: 2em 0; }
+table { width: 100%; border-collapse: collapse; margin: 2em 0; }
+th, td { padding: .4em; border-bottom: 1px solid #ccc; text-align: left; }
+.number { text-align: right; }
+.details { color: #666; }
+.total { font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>Invoice {{.Number}}</h1>
+<div class="parties">
+<div>{{range lines .Sender}}{{.}}<br>{{end}}</div>
+<div>{{if .Recipient}}<strong>Bill to:</strong><br>{{range lines .Recipient}}{{.}}<br>{{end}}{{end}}</div>
+</div>
+<p>
+Date: {{day .Issued}}<br>
+Project: {{.Project}}<br>
+{{if or (not .Since.IsZero) (not .Until.IsZero)}}Period: {{day .Since}} to {{day .Until}}<br>{{end}}
+</p>
+<table>
+<tr><th>{{if eq .GroupBy "day"}}Day{{else}}Task{{end}}</th><th class="number">Hours</th><th class="number">Rate</th><th class="number">Amount</th></tr>
+{{range .Lines}}<tr><td>{{.Label}}{{if .Details}} <span class="details">({{.Details}})</span>{{end}}</td><td class="number">{{hours .Hours}}</td><td class="number">{{money $.Currency .Rate}}</td><td class="number">{{money $.Currency .Amount}}</td></tr>
+{{end}}{{if .TaxRate.IsPositive}}<tr><td colspan="3">Subtotal</td><td class="number">{{money .Currency .Subtotal}}</td></tr>
+<tr><td colspan="3">Tax ({{percent .TaxRate}}%)</td><td class="number">{{money .Currency .Tax}}</td></tr>
+{{end}}<tr class="total"><td>Total</td><td class="number">{{hours .Hours}}</td><td></td><td class="number">{{money .Currency .Total}}</td></tr>
+</table>
+</body>
+</html>
+`
+
+// RenderInvoice writes the invoice as Markdown or as a standalone HTML page.
+func RenderInvoice(invoice *Invoice, format string) (string, error) {
+	var output strings.Builder
+	switch format {
+	case InvoiceFormatMarkdown:
+		tmpl, err := template.New("invoice").Funcs(invoiceFuncs).Parse(invoiceMarkdown)
+		if err != nil {
+			return "", err
+		}
+		err = tmpl.Execute(&output, invoice)
+		if err != nil {
+			return "", err
+		}
+	case InvoiceFormatHTML:
+		tmpl, err := htmltemplate.New("invoice").Funcs(invoiceFuncs).Parse(invoiceHTML)
+		if err != nil {
+			return "", err
+		}
+		err = tmpl.Execute(&output, invoice)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unknown format '%s'", format)
+	}
+	return output.String(), nil
+}
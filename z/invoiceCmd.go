@@ -0,0 +1,216 @@
+package z
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/jinzhu/now"
+	"github.com/spf13/cobra"
+)
+
+var invoiceGroupBy string
+var invoiceFormat string
+var invoiceOutput string
+var invoiceNumber string
+var invoiceDraft bool
+
+var invoiceCmd = &cobra.Command{
+	Use:   "invoice",
+	Short: "Write an invoice for a project",
+	Long: `Write an invoice for the finished activities of a project, as Markdown or as a standalone HTML page.
+
+The hours are billed at the rates set via 'zeit rate set'. The activities on the
+invoice are marked as invoiced and are not billed again, unless the invoice is
+voided with 'zeit invoice void'. Invoices are numbered
+per year, following the highest number, like 2024-0001, put ZEIT_INVOICE_PREFIX in front of it.
+Currency, tax rate, sender and recipient are read from ZEIT_CURRENCY, ZEIT_TAX_RATE,
+ZEIT_INVOICE_SENDER and ZEIT_INVOICE_RECIPIENT unless their flags are given,
+'\n' separates the lines of the sender and recipient.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if project == "" {
+			fmt.Printf("%s Please give the project to invoice via --project\n", CharError)
+			os.Exit(1)
+		}
+		if invoiceGroupBy != InvoiceGroupByTask && invoiceGroupBy != InvoiceGroupByDay {
+			fmt.Printf("%s --group-by has to be either %s or %s.\n", CharError, InvoiceGroupByTask, InvoiceGroupByDay)
+			os.Exit(1)
+		}
+		if invoiceFormat != InvoiceFormatMarkdown && invoiceFormat != InvoiceFormatHTML {
+			fmt.Printf("%s --format has to be either %s or %s.\n", CharError, InvoiceFormatMarkdown, InvoiceFormatHTML)
+			os.Exit(1)
+		}
+		tax, err := taxRate()
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+
+		var sinceDay, untilDay time.Time
+		if since != "" {
+			sinceDay, err = parseDay(since)
+			if err != nil {
+				fmt.Printf("%s could not parse --since '%s'. Error: %s\n", CharError, since, err.Error())
+				os.Exit(1)
+			}
+		}
+		if until != "" {
+			untilDay, err = parseDay(until)
+			if err != nil {
+				fmt.Printf("%s could not parse --until '%s'. Error: %s\n", CharError, until, err.Error())
+				os.Exit(1)
+			}
+		}
+		// Entries that are invoiced or written off already are never billed again.
+		query := EntryQuery{Project: project, Since: sinceDay, Finished: true, Billing: BillingUnbilled}
+		if !untilDay.IsZero() {
+			query.Until = now.With(untilDay).EndOfDay()
+		}
+		entries, err := database.QueryEntries(query)
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		if len(entries) == 0 {
+			fmt.Printf("%s there are no finished, unbilled activities of %s to invoice.\n", CharError, project)
+			os.Exit(1)
+		}
+
+		rates, err := database.GetRates(project)
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		invoice, err := NewInvoice(project, entries, rates, invoiceGroupBy)
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		invoice.Since = sinceDay
+		invoice.Until = untilDay
+		invoice.Currency = currency()
+		invoice.TaxRate = tax
+		invoice.Sender = sender()
+		invoice.Recipient = recipient()
+		invoice.Number = invoiceNumber
+
+		if invoiceDraft {
+			invoice.Number = "DRAFT"
+		} else {
+			err = database.AddInvoice(invoice)
+			if errors.Is(err, ErrInvoiceExists) {
+				fmt.Printf("%s there is an invoice %s already.\n", CharError, invoice.Number)
+				os.Exit(1)
+			}
+			if errors.Is(err, ErrEntriesBilled) {
+				fmt.Printf("%s some of the activities were billed in the meantime, nothing was invoiced.\n", CharError)
+				os.Exit(1)
+			}
+			if err != nil {
+				fmt.Printf("%s %+v\n", CharError, err)
+				os.Exit(1)
+			}
+		}
+
+		output, err := RenderInvoice(invoice, invoiceFormat)
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		if invoiceOutput == "" {
+			fmt.Print(output)
+			return
+		}
+		err = os.WriteFile(invoiceOutput, []byte(output), 0644)
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s wrote invoice %s over %s to %s\n", CharInfo,
+			color.FgLightWhite.Render(invoice.Number),
+			color.FgLightWhite.Render(formatMoney(invoice.Currency, invoice.Total())),
+			invoiceOutput)
+	},
+}
+
+var invoiceListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the invoices written so far",
+	Run: func(cmd *cobra.Command, args []string) {
+		invoices, err := database.GetInvoices()
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		if len(invoices) == 0 {
+			fmt.Printf("%s no invoices yet, write one with 'zeit invoice'\n", CharInfo)
+			return
+		}
+		for _, invoice := range invoices {
+			output := fmt.Sprintf("%s %s %s for %s, %s, %d activities", CharMore,
+				color.FgLightWhite.Render(invoice.Number),
+				invoice.Issued.Format(dayFormat),
+				color.FgLightWhite.Render(invoice.Project),
+				formatMoney(invoice.Currency, invoice.Total),
+				invoice.Entries)
+			if invoice.Voided {
+				output = fmt.Sprintf("%s %s", output, color.FgLightRed.Render("[voided]"))
+			}
+			fmt.Println(output)
+		}
+	},
+}
+
+var invoiceVoidCmd = &cobra.Command{
+	Use:   "void [number]",
+	Short: "Void an invoice",
+	Long:  "Void an invoice and release its activities, so they can be edited and invoiced again. The number of the invoice is not given out again.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		number := args[0]
+		released, err := database.VoidInvoice(number)
+		if errors.Is(err, sql.ErrNoRows) {
+			fmt.Printf("%s there is no invoice %s.\n", CharError, number)
+			os.Exit(1)
+		}
+		if errors.Is(err, ErrInvoiceVoided) {
+			fmt.Printf("%s invoice %s is voided already.\n", CharError, number)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s voided invoice %s, %d activities are unbilled again\n", CharErase, color.FgLightWhite.Render(number), released)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(invoiceCmd)
+	invoiceCmd.AddCommand(invoiceListCmd)
+	invoiceCmd.AddCommand(invoiceVoidCmd)
+	invoiceCmd.Flags().StringVarP(&project, "project", "p", "", "Project to invoice")
+	invoiceCmd.Flags().StringVar(&since, "since", "", "First day to invoice, e.g. 2024-10-01")
+	invoiceCmd.Flags().StringVar(&until, "until", "", "Last day to invoice, e.g. 2024-10-31")
+	invoiceCmd.Flags().StringVar(&invoiceGroupBy, "group-by", InvoiceGroupByTask, "One line per task or per day: task or day")
+	invoiceCmd.Flags().StringVar(&invoiceFormat, "format", InvoiceFormatMarkdown, "Format of the invoice: md or html")
+	invoiceCmd.Flags().StringVarP(&invoiceOutput, "output", "o", "", "File to write the invoice to instead of printing it")
+	invoiceCmd.Flags().StringVar(&invoiceCurrency, "currency", "", "Currency of the amounts (default ZEIT_CURRENCY or EUR)")
+	invoiceCmd.Flags().StringVar(&invoiceTaxRate, "tax", "", "Tax rate in percent, e.g. 19 (default ZEIT_TAX_RATE or none)")
+	invoiceCmd.Flags().StringVar(&invoiceSender, "sender", "", "Your name and address (default ZEIT_INVOICE_SENDER)")
+	invoiceCmd.Flags().StringVar(&invoiceRecipient, "recipient", "", "Name and address of the client (default ZEIT_INVOICE_RECIPIENT)")
+	invoiceCmd.Flags().StringVar(&invoiceNumber, "number", "", "Number of the invoice instead of the next one")
+	invoiceCmd.Flags().BoolVar(&invoiceDraft, "draft", false, "Only show the invoice, without numbering it")
+
+	registerProjectTaskCompletion(invoiceCmd, false)
+
+	var err error
+	database, err = InitDB()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,53 @@
+package z
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInvoiceNumbersFollowTheHighest(t *testing.T) {
+	t.Setenv("ZEIT_INVOICE_PREFIX", "INV-")
+	db := newTestDatabase(t)
+	issued := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var numbers []string
+	for _, given := range []string{"", "INV-2026-0005", "INV-2026-0005b", "", "INV-2025-0042", ""} {
+		invoice := &Invoice{Number: given, Issued: issued, Project: "Zeit"}
+		err := db.AddInvoice(invoice)
+		if err != nil {
+			t.Fatalf("adding invoice %q: %v", given, err)
+		}
+		numbers = append(numbers, invoice.Number)
+	}
+	want := []string{"INV-2026-0001", "INV-2026-0005", "INV-2026-0005b", "INV-2026-0006", "INV-2025-0042", "INV-2026-0007"}
+	for i := range want {
+		if numbers[i] != want[i] {
+			t.Errorf("invoice %d is numbered %q, want %q", i+1, numbers[i], want[i])
+		}
+	}
+}
+
+func TestImportBillingKeepsOnlyKnownInvoices(t *testing.T) {
+	invoices := []InvoiceRecord{{Number: "2026-0001"}, {Number: "2026-0002", Voided: true}}
+	tests := []struct {
+		name          string
+		state, number string
+		wantState     string
+		wantNumber    string
+	}{
+		{"known invoice", BillingInvoiced, "2026-0001", BillingInvoiced, "2026-0001"},
+		{"unknown invoice", BillingInvoiced, "2025-0042", BillingUnbilled, ""},
+		{"voided invoice", BillingInvoiced, "2026-0002", BillingUnbilled, ""},
+		{"written off", BillingWrittenOff, "", BillingWrittenOff, ""},
+		{"exported before billing", "", "", BillingUnbilled, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entry := Entry{BillingState: test.state, InvoiceNumber: test.number}
+			importBilling(&entry, invoices)
+			if entry.BillingState != test.wantState || entry.InvoiceNumber != test.wantNumber {
+				t.Errorf("imported entry is %q on %q, want %q on %q", entry.BillingState, entry.InvoiceNumber, test.wantState, test.wantNumber)
+			}
+		})
+	}
+}
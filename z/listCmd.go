@@ -5,6 +5,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/gookit/color"
 	"github.com/jinzhu/now"
 	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
@@ -14,19 +15,20 @@ var listTotalTime bool
 var listOnlyProjectsAndTasks bool
 var listOnlyTasks bool
 var appendProjectIDToTask bool
+var listOnlyRunning bool
+var listLimit int
+var listOrder string
+var listWithCommits bool
+var listUnbilled bool
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List activities",
 	Long:  "List all tracked activities.",
 	Run: func(cmd *cobra.Command, args []string) {
+		expandProjectFilter()
 
-		entries, err := database.GetAllEntries()
-		if err != nil {
-			fmt.Printf("%s %+v\n", CharError, err)
-			os.Exit(1)
-		}
-
+		var err error
 		var sinceTime time.Time
 		var untilTime time.Time
 
@@ -47,7 +49,19 @@ var listCmd = &cobra.Command{
 		}
 
 		var filteredEntries []Entry
-		filteredEntries, err = GetFilteredEntries(entries, project, task, sinceTime, untilTime)
+		query := EntryQuery{
+			Project: project,
+			Task:    task,
+			Since:   sinceTime,
+			Until:   untilTime,
+			Running: listOnlyRunning,
+			Limit:   listLimit,
+			Order:   listOrder,
+		}
+		if listUnbilled {
+			query.Billing = BillingUnbilled
+		}
+		filteredEntries, err = database.QueryEntries(query)
 		if err != nil {
 			fmt.Printf("%s %+v\n", CharError, err)
 			os.Exit(1)
@@ -90,9 +104,25 @@ var listCmd = &cobra.Command{
 		}
 
 		totalHours := decimal.NewFromInt(0)
+		max := maxRunningDuration()
 		for _, entry := range filteredEntries {
 			totalHours = totalHours.Add(entry.GetDuration())
-			fmt.Printf("%s\n", entry.GetOutput(false))
+			output := entry.GetOutput(false)
+			if entry.IsForgotten(max) {
+				output = fmt.Sprintf("%s %s", output, color.FgLightRed.Render(fmt.Sprintf("[longer than %sh]", fmtDuration(max))))
+			}
+			if billing := getOutputForBilling(entry); billing != "" {
+				output = fmt.Sprintf("%s %s", output, color.FgGray.Render(billing))
+			}
+			fmt.Printf("%s\n", output)
+			if listWithCommits {
+				commits, err := GetCommits(entry)
+				if err != nil {
+					fmt.Printf("%s %+v\n", CharError, err)
+					os.Exit(1)
+				}
+				fmt.Print(GetOutputForCommits(commits))
+			}
 		}
 
 		if listTotalTime {
@@ -105,14 +135,22 @@ func init() {
 	rootCmd.AddCommand(listCmd)
 	listCmd.Flags().StringVar(&since, "since", "", "Date/time to start the list from")
 	listCmd.Flags().StringVar(&until, "until", "", "Date/time to list until")
-	listCmd.Flags().StringVarP(&project, "project", "p", "", "Project to be listed")
+	listCmd.Flags().StringVarP(&project, "project", "p", "", "Project to be listed, or an @alias")
 	listCmd.Flags().StringVarP(&task, "task", "t", "", "Task to be listed")
 	listCmd.Flags().BoolVar(&fractional, "decimal", true, "Show fractional hours in decimal format instead of minutes")
 	listCmd.Flags().BoolVar(&listTotalTime, "total", false, "Show total time of hours for listed activities")
 	listCmd.Flags().BoolVar(&listOnlyProjectsAndTasks, "only-projects-and-tasks", false, "Only list projects and their tasks, no entries")
 	listCmd.Flags().BoolVar(&listOnlyTasks, "only-tasks", false, "Only list tasks, no projects nor entries")
+	listCmd.Flags().BoolVar(&listOnlyRunning, "running", false, "Only list running activities")
+	listCmd.Flags().BoolVar(&listUnbilled, "unbilled", false, "Only list activities that are neither invoiced nor written off")
+	listCmd.Flags().IntVar(&listLimit, "limit", 0, "Only list this many activities")
+	listCmd.Flags().StringVar(&listOrder, "order", OrderByBegin, "Order of the listed activities, possible values: begin, begin-desc, finish, finish-desc, hours, hours-desc")
+	listCmd.Flags().DurationVar(&maxRunning, "max-running", 0, "Flag activities longer than this as suspicious (default ZEIT_MAX_RUNNING or 12h)")
+	listCmd.Flags().BoolVar(&listWithCommits, "with-commits", false, "Show the commits made to the project's repositories during each activity")
 	listCmd.Flags().BoolVar(&appendProjectIDToTask, "append-project-id-to-task", false, "Append project ID to tasks in the list")
 
+	registerProjectTaskCompletion(listCmd, true)
+
 	var err error
 	database, err = InitDB()
 	if err != nil {
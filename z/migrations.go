@@ -0,0 +1,498 @@
+package z
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Migration is a single, ordered step of the database schema.
+// Versions have to be strictly increasing and must never be changed once released,
+// new schema changes always get appended to the end of 'migrations'.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, tx *sql.Tx) error
+}
+
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create entries table",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS entries(
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				date  TEXT NOT NULL,
+				start TEXT NOT NULL,
+				finish TEXT,
+				hours  FLOAT,
+				project TEXT NOT NULL,
+				task   TEXT NOT NULL,
+				notes  TEXT,
+				running BOOL);`)
+			return err
+		},
+	},
+	{
+		Version:     2,
+		Description: "store begin/finish as unix timestamps and hours as seconds",
+		Up:          migrateToTimestamps,
+	},
+	{
+		Version:     3,
+		Description: "allow only a single running entry",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			var runningEntries int
+			err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM entries WHERE running = 1;`).Scan(&runningEntries)
+			if err != nil {
+				return err
+			}
+			if runningEntries > 1 {
+				// The index can not be built yet, 'zeit finish' offers to repair this and creates it afterwards.
+				fmt.Printf("%s the database contains %d running entries, run 'zeit finish' to repair it\n", CharError, runningEntries)
+				return nil
+			}
+			_, err = tx.ExecContext(ctx, `CREATE UNIQUE INDEX IF NOT EXISTS entries_single_running ON entries(running) WHERE running = 1;`)
+			return err
+		},
+	},
+	{
+		Version:     4,
+		Description: "index entries by project and task",
+		Up:          migrateProjectAndTaskKeys,
+	},
+	{
+		Version:     5,
+		Description: "create pauses table",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `CREATE TABLE pauses(
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				entry_id INTEGER NOT NULL REFERENCES entries(id),
+				start INTEGER NOT NULL,
+				start_offset INTEGER NOT NULL DEFAULT 0,
+				finish INTEGER,
+				finish_offset INTEGER);
+				CREATE INDEX pauses_entry_id ON pauses(entry_id, start);`)
+			return err
+		},
+	},
+	{
+		Version:     6,
+		Description: "mark completed pomodoros",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `ALTER TABLE entries ADD COLUMN pomodoro INTEGER NOT NULL DEFAULT 0;`)
+			return err
+		},
+	},
+	{
+		Version:     7,
+		Description: "allow parallel entries next to the single running one",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `ALTER TABLE entries ADD COLUMN parallel INTEGER NOT NULL DEFAULT 0;
+				DROP INDEX IF EXISTS entries_single_running;`)
+			if err != nil {
+				return err
+			}
+			var runningEntries int
+			err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM entries WHERE running = 1;`).Scan(&runningEntries)
+			if err != nil {
+				return err
+			}
+			if runningEntries > 1 {
+				// Same as in version 3, 'zeit finish' creates the index once the database is repaired.
+				return nil
+			}
+			_, err = tx.ExecContext(ctx, singleRunningIndexQuery)
+			return err
+		},
+	},
+	{
+		Version:     8,
+		Description: "create aliases table",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `CREATE TABLE aliases(
+				name TEXT PRIMARY KEY,
+				project TEXT NOT NULL,
+				task TEXT NOT NULL DEFAULT '',
+				notes TEXT NOT NULL DEFAULT '');`)
+			return err
+		},
+	},
+	{
+		Version:     9,
+		Description: "create recurring entries and holidays tables",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `CREATE TABLE recurring(
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				weekdays INTEGER NOT NULL,
+				at TEXT NOT NULL,
+				seconds INTEGER NOT NULL,
+				project TEXT NOT NULL,
+				task TEXT NOT NULL,
+				notes TEXT NOT NULL DEFAULT '',
+				since TEXT NOT NULL,
+				last_applied TEXT);
+				CREATE TABLE recurring_runs(
+				recurring_id INTEGER NOT NULL REFERENCES recurring(id),
+				day TEXT NOT NULL,
+				entry_id INTEGER REFERENCES entries(id),
+				PRIMARY KEY (recurring_id, day));
+				CREATE TABLE holidays(
+				day TEXT PRIMARY KEY,
+				name TEXT NOT NULL DEFAULT '');`)
+			return err
+		},
+	},
+	{
+		Version:     10,
+		Description: "map projects to git repositories",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `CREATE TABLE project_repos(
+				project TEXT NOT NULL,
+				project_key TEXT NOT NULL,
+				path TEXT NOT NULL,
+				PRIMARY KEY (project_key, path));`)
+			return err
+		},
+	},
+	{
+		Version:     11,
+		Description: "create project detection rules table",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `CREATE TABLE project_rules(
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				kind TEXT NOT NULL,
+				pattern TEXT NOT NULL,
+				project TEXT NOT NULL);`)
+			return err
+		},
+	},
+	{
+		Version:     12,
+		Description: "create projects table with budgets",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `CREATE TABLE projects(
+				key TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				budget_hours TEXT,
+				budget_period TEXT NOT NULL DEFAULT 'total');`)
+			return err
+		},
+	},
+	{
+		Version:     13,
+		Description: "add hourly rates and invoices",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `ALTER TABLE projects ADD COLUMN rate TEXT;
+				CREATE TABLE task_rates(
+				project_key TEXT NOT NULL,
+				task_key TEXT NOT NULL,
+				project TEXT NOT NULL,
+				task TEXT NOT NULL,
+				rate TEXT NOT NULL,
+				PRIMARY KEY (project_key, task_key));
+				CREATE TABLE invoices(
+				number TEXT PRIMARY KEY,
+				project TEXT NOT NULL,
+				issued TEXT NOT NULL,
+				since TEXT NOT NULL DEFAULT '',
+				until TEXT NOT NULL DEFAULT '',
+				currency TEXT NOT NULL,
+				total TEXT NOT NULL);`)
+			return err
+		},
+	},
+	{
+		Version:     14,
+		Description: "track the billing state of entries",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `ALTER TABLE entries ADD COLUMN billing_state TEXT NOT NULL DEFAULT 'unbilled';
+				ALTER TABLE entries ADD COLUMN invoice_number TEXT REFERENCES invoices(number);
+				CREATE INDEX entries_invoice_number ON entries(invoice_number);
+				ALTER TABLE invoices ADD COLUMN voided TEXT;`)
+			return err
+		},
+	},
+}
+
+// singleRunningIndexQuery keeps more than one entry from running, parallel entries are not counted.
+const singleRunningIndexQuery = `CREATE UNIQUE INDEX IF NOT EXISTS entries_single_running ON entries(running) WHERE running = 1 AND parallel = 0;`
+
+// migrateToTimestamps rebuilds the entries table with sortable, exact columns.
+// The old text timestamps are parsed the same way imports are. Rows that can not be parsed
+// are moved to 'entries_unparsed' untouched, so nothing is lost and they can be fixed by hand.
+func migrateToTimestamps(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `CREATE TABLE entries_v2(
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			date TEXT NOT NULL,
+			start INTEGER NOT NULL,
+			start_offset INTEGER NOT NULL DEFAULT 0,
+			finish INTEGER,
+			finish_offset INTEGER,
+			seconds INTEGER NOT NULL DEFAULT 0,
+			project TEXT NOT NULL,
+			task TEXT NOT NULL,
+			notes TEXT NOT NULL DEFAULT '',
+			running INTEGER NOT NULL DEFAULT 0);`)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS entries_unparsed AS SELECT * FROM entries WHERE 0;`)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, date, start, finish, hours, project, task, notes, running FROM entries;`)
+	if err != nil {
+		return err
+	}
+	var legacyEntries []EntryDB
+	for rows.Next() {
+		var entryDB EntryDB
+		var finish, hours, notes, running sql.NullString
+		err := rows.Scan(&entryDB.ID, &entryDB.Date, &entryDB.Begin, &finish, &hours, &entryDB.Project, &entryDB.Task, &notes, &running)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		entryDB.Finish = finish.String
+		entryDB.Hours = "0"
+		entryDB.Notes = notes.String
+		entryDB.Running = running.String == "true" || running.String == "1"
+		legacyEntries = append(legacyEntries, entryDB)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var unparsed int
+	for _, entryDB := range legacyEntries {
+		entry, err := entryDB.ConvertToEntry()
+		if err != nil {
+			fmt.Printf("%s could not convert entry %s (begin: '%s', finish: '%s'). Error: %s\n", CharError, entryDB.ID, entryDB.Begin, entryDB.Finish, err.Error())
+			_, err = tx.ExecContext(ctx, `INSERT INTO entries_unparsed SELECT * FROM entries WHERE id = ?;`, entryDB.ID)
+			if err != nil {
+				return err
+			}
+			unparsed++
+			continue
+		}
+		if entry.Running {
+			entry.Finish = time.Time{}
+		} else {
+			entry.Hours = entry.GetDuration()
+		}
+		row := NewEntryRow(*entry)
+		_, err = tx.ExecContext(ctx, `INSERT INTO entries_v2(id, date, start, start_offset, finish, finish_offset, seconds, project, task, notes, running)
+			VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+			row.ID, row.Date, row.Start, row.StartOffset, row.Finish, row.FinishOffset, row.Seconds, row.Project, row.Task, row.Notes, row.Running)
+		if err != nil {
+			return err
+		}
+	}
+	if unparsed > 0 {
+		fmt.Printf("%s %d entries could not be converted and were moved to the table 'entries_unparsed'\n", CharError, unparsed)
+	}
+
+	for _, query := range []string{
+		`DROP TABLE entries;`,
+		`ALTER TABLE entries_v2 RENAME TO entries;`,
+		`CREATE INDEX entries_start ON entries(start);`,
+	} {
+		_, err = tx.ExecContext(ctx, query)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// autoMigrate reports if pending migrations are applied before every command.
+// Setting 'ZEIT_AUTO_MIGRATE=false' leaves that to 'zeit db migrate'.
+func autoMigrate() bool {
+	value, ok := os.LookupEnv("ZEIT_AUTO_MIGRATE")
+	if !ok {
+		return true
+	}
+	switch strings.ToLower(value) {
+	case "0", "false", "no", "off":
+		return false
+	}
+	return true
+}
+
+func (db *Database) createSchemaVersionTable() error {
+	query := `CREATE TABLE IF NOT EXISTS schema_version(
+			version INTEGER PRIMARY KEY,
+			description TEXT NOT NULL,
+			applied_at TEXT NOT NULL);`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err := db.DB.ExecContext(ctx, query)
+	return err
+}
+
+func (db *Database) SchemaVersion() (int, error) {
+	err := db.createSchemaVersionTable()
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	var version int
+	err = db.DB.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_version;`).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+func (db *Database) PendingMigrations() ([]Migration, error) {
+	version, err := db.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, m := range migrations {
+		if m.Version > version {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Migrate applies all pending migrations in order and returns the ones it applied.
+// A backup of the database file is taken before the first migration runs,
+// every migration is run in its own transaction together with its 'schema_version' row.
+func (db *Database) Migrate() ([]Migration, error) {
+	pending, err := db.PendingMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	err = db.backup(pending[0].Version - 1)
+	if err != nil {
+		return nil, fmt.Errorf("could not back up database before migrating: %w", err)
+	}
+	var applied []Migration
+	for _, m := range pending {
+		err := db.applyMigration(m)
+		if err != nil {
+			return applied, fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Description, err)
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+func (db *Database) applyMigration(m Migration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	err = m.Up(ctx, tx)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO schema_version(version, description, applied_at) VALUES(?, ?, ?);`,
+		m.Version, m.Description, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// backup copies the database next to the original file, e.g. 'zeit.db.v1-20240822T204400.bak'.
+// New or in-memory databases have nothing worth saving and are skipped.
+func (db *Database) backup(version int) error {
+	if db.Path == "" || strings.Contains(db.Path, ":memory:") {
+		return nil
+	}
+	info, err := os.Stat(db.Path)
+	if err != nil || info.Size() == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	var tables int
+	err = db.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name NOT IN ('schema_version', 'sqlite_sequence');`).Scan(&tables)
+	if err != nil {
+		return err
+	}
+	if tables == 0 {
+		return nil
+	}
+	backupPath := fmt.Sprintf("%s.v%d-%s.bak", db.Path, version, time.Now().Format("20060102T150405"))
+	_, err = db.DB.ExecContext(ctx, `VACUUM INTO ?;`, backupPath)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%s backed up database to '%s' before migrating\n", CharInfo, backupPath)
+	return nil
+}
+
+// migrateProjectAndTaskKeys stores GetIdFromName of project and task next to them,
+// so filtering on them can use an index instead of loading every entry.
+func migrateProjectAndTaskKeys(ctx context.Context, tx *sql.Tx) error {
+	for _, query := range []string{
+		`ALTER TABLE entries ADD COLUMN project_key TEXT NOT NULL DEFAULT '';`,
+		`ALTER TABLE entries ADD COLUMN task_key TEXT NOT NULL DEFAULT '';`,
+	} {
+		_, err := tx.ExecContext(ctx, query)
+		if err != nil {
+			return err
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT DISTINCT project, task FROM entries;`)
+	if err != nil {
+		return err
+	}
+	var pairs [][2]string
+	for rows.Next() {
+		var pair [2]string
+		err := rows.Scan(&pair[0], &pair[1])
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		pairs = append(pairs, pair)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `CREATE INDEX entries_project_task_tmp ON entries(project, task);`)
+	if err != nil {
+		return err
+	}
+	for _, pair := range pairs {
+		_, err := tx.ExecContext(ctx, `UPDATE entries SET project_key = ?, task_key = ? WHERE project = ? AND task = ?;`,
+			GetIdFromName(pair[0]), GetIdFromName(pair[1]), pair[0], pair[1])
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, query := range []string{
+		`DROP INDEX entries_project_task_tmp;`,
+		`CREATE INDEX entries_project_key_start ON entries(project_key, start);`,
+		`CREATE INDEX entries_task_key_start ON entries(task_key, start);`,
+		`CREATE INDEX entries_finish ON entries(finish);`,
+	} {
+		_, err := tx.ExecContext(ctx, query)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
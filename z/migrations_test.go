@@ -0,0 +1,123 @@
+package z
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// newTestDatabase opens an empty, fully migrated database in a temporary directory.
+func newTestDatabase(tb testing.TB) *Database {
+	tb.Helper()
+	db, err := openDatabase(filepath.Join(tb.TempDir(), "zeit.db"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { db.DB.Close() })
+	_, err = db.Migrate()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return db
+}
+
+func TestMigrateBaselineDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zeit.db")
+	db, err := openDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.DB.Close()
+
+	// The schema and the values as the releases before the migrations wrote them.
+	_, err = db.DB.ExecContext(context.Background(), `CREATE TABLE IF NOT EXISTS entries(
+			ID INTEGER PRIMARY KEY AUTOINCREMENT,
+			date  TEXT NOT NULL,
+			start TEXT NOT NULL,
+			finish TEXT,
+			hours  FLOAT,
+			project TEXT NOT NULL,
+			task   TEXT NOT NULL,
+			notes  TEXT,
+			running BOOL);
+		INSERT INTO entries(date, start, finish, hours, project, task, notes, running) VALUES
+			('22-08-2024', '2024-08-22 07:00:00 +0000 UTC', '2024-08-22 08:30:00 +0000 UTC', '1.5', 'Zeit', 'Migrations', 'done', 'false'),
+			('23-08-2024', '2024-08-23 09:15:00 +0000 UTC', '0001-01-01 00:00:00 +0000 UTC', '0.0', 'Zeit', 'Review', '', 'true');`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 0 {
+		t.Fatalf("schema version of a baseline database is %d, want 0", version)
+	}
+	pending, err := db.PendingMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != len(migrations) {
+		t.Fatalf("%d pending migrations, want all %d", len(pending), len(migrations))
+	}
+
+	applied, err := db.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(migrations) {
+		t.Fatalf("applied %d migrations, want %d", len(applied), len(migrations))
+	}
+	version, err = db.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest := migrations[len(migrations)-1].Version; version != latest {
+		t.Fatalf("schema version after migrating is %d, want %d", version, latest)
+	}
+	backups, err := filepath.Glob(path + ".v0-*.bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("found %d backups of the baseline database, want 1", len(backups))
+	}
+
+	finished, err := db.GetEntry(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if finished.Running || finished.Task != "Migrations" || finished.Notes != "done" {
+		t.Errorf("finished entry migrated to %+v", finished)
+	}
+	if got := finished.Finish.Sub(finished.Begin).Minutes(); got != 90 {
+		t.Errorf("finished entry lasts %v minutes, want 90", got)
+	}
+	if finished.BillingState != BillingUnbilled {
+		t.Errorf("billing state of a migrated entry is %q, want %q", finished.BillingState, BillingUnbilled)
+	}
+
+	running, err := db.GetRunningEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if running.ID != 2 || running.Task != "Review" {
+		t.Errorf("running entry migrated to %+v", running)
+	}
+
+	// A second run has nothing left to do.
+	applied, err = db.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("migrating twice applied %d migrations", len(applied))
+	}
+	_, err = db.GetEntry(3)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetEntry of a missing entry returned %v, want sql.ErrNoRows", err)
+	}
+}
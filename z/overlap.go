@@ -0,0 +1,129 @@
+package z
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OverlapError is returned when an entry would cover the time of other entries and 'ZEIT_OVERLAP=reject' is set.
+type OverlapError struct {
+	Entry       Entry
+	Overlapping []Entry
+}
+
+func (err *OverlapError) Error() string {
+	ids := make([]string, 0, len(err.Overlapping))
+	for _, entry := range err.Overlapping {
+		ids = append(ids, fmt.Sprint(entry.ID))
+	}
+	return fmt.Sprintf("%s on %s overlaps with entry %s; set ZEIT_OVERLAP=warn to save it anyway",
+		err.Entry.Task, err.Entry.Project, strings.Join(ids, ", "))
+}
+
+// Overlap is a pair of finished entries covering the same time, First is the one that began earlier.
+type Overlap struct {
+	First  Entry
+	Second Entry
+}
+
+// Duration is how long both entries cover the same time.
+func (o Overlap) Duration() time.Duration {
+	return overlap(o.First.Begin, o.First.end(), o.Second.Begin, o.Second.end())
+}
+
+// findOverlaps returns the entries whose time overlaps with entry, running entries reach until now.
+// Touching entries, where one finishes the very second the other begins, do not overlap,
+// neither do parallel entries since they are meant to run alongside others.
+func findOverlaps(ctx context.Context, tx execer, entry Entry) ([]Entry, error) {
+	row := NewEntryRow(entry)
+	now := time.Now().Unix()
+	finish := now
+	if row.Finish.Valid {
+		finish = row.Finish.Int64
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT `+entryColumns+` FROM entries
+			WHERE id != ? AND parallel = 0 AND start < ? AND COALESCE(finish, ?) > ?
+			ORDER BY start;`,
+		row.ID, finish, now, row.Start)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []Entry
+	for rows.Next() {
+		other, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *other)
+	}
+	return entries, rows.Err()
+}
+
+// checkOverlaps applies the overlap mode to entry before it is saved: nothing happens with 'ignore',
+// 'warn' returns the overlapping entries for the command to show and 'reject' returns an OverlapError.
+func checkOverlaps(ctx context.Context, tx execer, entry Entry) ([]Entry, error) {
+	mode := overlapMode()
+	if mode == OverlapIgnore || entry.Parallel {
+		return nil, nil
+	}
+	overlapping, err := findOverlaps(ctx, tx, entry)
+	if err != nil || len(overlapping) == 0 {
+		return nil, err
+	}
+	if mode == OverlapReject {
+		return nil, &OverlapError{Entry: entry, Overlapping: overlapping}
+	}
+	return overlapping, nil
+}
+
+// warnOverlaps prints the entries that entry was saved over in 'warn' mode, if there are any.
+func warnOverlaps(entry Entry, overlapping []Entry) {
+	if len(overlapping) == 0 {
+		return
+	}
+	fmt.Printf("%s %s on %s overlaps with:\n", CharError, entry.Task, entry.Project)
+	for _, other := range overlapping {
+		fmt.Printf("   %s\n", other.GetOutput(false))
+	}
+	fmt.Printf("   Type 'zeit overlaps' to resolve this.\n")
+}
+
+// overlapPairsQuery selects the ids of all pairs of finished, non-parallel entries that overlap, the earlier one first.
+const overlapPairsQuery = `SELECT a.id AS first_id, b.id AS second_id FROM entries a
+		JOIN entries b ON b.start >= a.start AND b.start < a.finish AND b.id != a.id AND (b.start > a.start OR b.id > a.id)
+		WHERE a.running = 0 AND b.running = 0 AND a.parallel = 0 AND b.parallel = 0`
+
+// GetOverlaps returns all pairs of finished entries that cover the same time, ordered by when they began.
+func (db *Database) GetOverlaps() ([]Overlap, error) {
+	entries, err := db.queryEntries(`WITH pairs AS (` + overlapPairsQuery + `)
+			SELECT ` + entryColumns + ` FROM entries
+			WHERE id IN (SELECT first_id FROM pairs UNION SELECT second_id FROM pairs);`)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	byID := make(map[int64]Entry, len(entries))
+	for _, entry := range entries {
+		byID[entry.ID] = entry
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	rows, err := db.DB.QueryContext(ctx, overlapPairsQuery+` ORDER BY a.start, b.start, a.id, b.id;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var overlaps []Overlap
+	for rows.Next() {
+		var firstID, secondID int64
+		err := rows.Scan(&firstID, &secondID)
+		if err != nil {
+			return nil, err
+		}
+		overlaps = append(overlaps, Overlap{First: byID[firstID], Second: byID[secondID]})
+	}
+	return overlaps, rows.Err()
+}
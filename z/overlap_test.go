@@ -0,0 +1,110 @@
+package z
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPlanOverlapFixClipsPauses(t *testing.T) {
+	day := time.Date(2024, 8, 22, 0, 0, 0, 0, time.UTC)
+	at := func(hour int, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	pause := func(fromHour, fromMinute, toHour, toMinute int) Pause {
+		return Pause{Begin: at(fromHour, fromMinute), Finish: at(toHour, toMinute)}
+	}
+	// 9:00 to 13:00 with a pause before, across and after 11:00.
+	earlier := Entry{ID: 1, Begin: at(9, 0), Finish: at(13, 0), Pauses: []Pause{
+		pause(9, 30, 9, 45), pause(10, 50, 11, 10), pause(12, 0, 12, 30),
+	}}
+
+	tests := []struct {
+		name     string
+		later    Entry
+		strategy string
+		update   []Pause
+		add      []Pause
+	}{
+		{
+			name:     "trim the end",
+			later:    Entry{ID: 2, Begin: at(11, 0), Finish: at(14, 0)},
+			strategy: resolveTrim,
+			update:   []Pause{pause(9, 30, 9, 45), pause(10, 50, 11, 0)},
+		},
+		{
+			name:     "trim the beginning",
+			later:    Entry{ID: 2, Begin: at(9, 0), Finish: at(11, 0)},
+			strategy: resolveTrim,
+			update:   []Pause{pause(11, 0, 11, 10), pause(12, 0, 12, 30)},
+		},
+		{
+			name:     "split",
+			later:    Entry{ID: 2, Begin: at(11, 0), Finish: at(12, 15)},
+			strategy: resolveSplit,
+			update:   []Pause{pause(9, 30, 9, 45), pause(10, 50, 11, 0)},
+			add:      []Pause{pause(12, 15, 12, 30)},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fix, err := planOverlapFix(Overlap{First: earlier, Second: test.later}, test.strategy, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fix.update == nil || !reflect.DeepEqual(fix.update.Pauses, test.update) {
+				t.Errorf("pauses of the trimmed activity are %+v, want %+v", fix.update, test.update)
+			}
+			if test.add != nil && (fix.add == nil || !reflect.DeepEqual(fix.add.Pauses, test.add)) {
+				t.Errorf("pauses of the split off activity are %+v, want %+v", fix.add, test.add)
+			}
+		})
+	}
+}
+
+func TestPlanOverlapFixSkipsInvoiced(t *testing.T) {
+	day := time.Date(2024, 8, 22, 0, 0, 0, 0, time.UTC)
+	earlier := Entry{ID: 1, Begin: day.Add(9 * time.Hour), Finish: day.Add(11 * time.Hour), BillingState: BillingInvoiced, InvoiceNumber: "2024-0001"}
+	later := Entry{ID: 2, Begin: day.Add(10 * time.Hour), Finish: day.Add(12 * time.Hour), BillingState: BillingUnbilled}
+	o := Overlap{First: earlier, Second: later}
+
+	_, err := planOverlapFix(o, resolveTrim, false)
+	if err == nil {
+		t.Error("trimming an invoiced activity was planned without force")
+	}
+	fix, err := planOverlapFix(o, resolveTrim, true)
+	if err != nil || fix.update == nil || fix.update.ID != earlier.ID {
+		t.Errorf("trimming an invoiced activity with force planned %+v, %v", fix, err)
+	}
+	// Deleting takes the later one, which is not invoiced.
+	fix, err = planOverlapFix(o, resolveDelete, false)
+	if err != nil || fix.delete == nil || fix.delete.ID != later.ID {
+		t.Errorf("deleting the unbilled activity planned %+v, %v", fix, err)
+	}
+}
+
+func TestAddEntryReturnsOverlaps(t *testing.T) {
+	db := newTestDatabase(t)
+	day := time.Date(2024, 8, 22, 0, 0, 0, 0, time.UTC)
+	seedEntries(t, db, day.Add(9*time.Hour), 1)
+	t.Cleanup(func() { overlapPolicy = "" })
+
+	overlapPolicy = OverlapWarn
+	entry := Entry{Project: "Acme", Task: "Review", Begin: day.Add(9*time.Hour + 15*time.Minute), Finish: day.Add(10 * time.Hour)}
+	overlapping, err := db.AddEntry(&entry, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overlapping) != 1 || overlapping[0].ID != 1 {
+		t.Errorf("saving over entry 1 returned the overlaps %+v, want entry 1", overlapping)
+	}
+
+	overlapPolicy = OverlapReject
+	entry = Entry{Project: "Acme", Task: "Review", Begin: day.Add(9*time.Hour + 20*time.Minute), Finish: day.Add(9*time.Hour + 30*time.Minute)}
+	_, err = db.AddEntry(&entry, false)
+	var overlapErr *OverlapError
+	if !errors.As(err, &overlapErr) || len(overlapErr.Overlapping) != 2 {
+		t.Errorf("saving over two entries in reject mode returned %v, want an OverlapError with both", err)
+	}
+}
@@ -0,0 +1,220 @@
+package z
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	resolveTrim   = "trim"
+	resolveSplit  = "split"
+	resolveDelete = "delete"
+)
+
+var overlapsResolve string
+
+var overlapsCmd = &cobra.Command{
+	Use:   "overlaps",
+	Short: "List and resolve overlapping activities",
+	Long: `List all pairs of finished activities that cover the same time.
+
+With --resolve every pair is fixed, the earlier one is the one that began first:
+  trim    cut the overlapping time off the earlier activity
+  split   like trim, but an earlier activity that surrounds the later one
+          is split into the parts before and after it
+  delete  erase the activity that was added last (higher id)
+Invoiced activities are skipped, unless --force is given.
+
+Saving activities that overlap others is handled by 'ZEIT_OVERLAP':
+warn (default), reject or ignore.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		switch overlapsResolve {
+		case "", resolveTrim, resolveSplit, resolveDelete:
+		default:
+			fmt.Printf("%s --resolve has to be one of trim, split or delete.\n", CharError)
+			os.Exit(1)
+		}
+		overlaps, err := database.GetOverlaps()
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		if len(overlaps) == 0 {
+			fmt.Printf("%s no overlapping activities\n", CharInfo)
+			return
+		}
+		if overlapsResolve == "" {
+			for _, o := range overlaps {
+				printOverlap(o)
+			}
+			fmt.Printf("%s %d overlapping pairs, resolve them with --resolve trim|split|delete\n", CharInfo, len(overlaps))
+			return
+		}
+
+		// The entries get changed on the way, so a fix must not be checked against the old state.
+		overlapPolicy = OverlapIgnore
+		for _, o := range overlaps {
+			resolveOverlap(o)
+		}
+		if dryRun {
+			return
+		}
+		remaining, err := database.GetOverlaps()
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		if len(remaining) > 0 {
+			fmt.Printf("%s %d overlapping pairs are left\n", CharError, len(remaining))
+			os.Exit(1)
+		}
+	},
+}
+
+func printOverlap(o Overlap) {
+	fmt.Printf("%s %sh overlap\n   %s\n   %s\n", CharMore,
+		color.FgLightWhite.Render(fmtDuration(o.Duration())),
+		o.First.GetOutput(false),
+		o.Second.GetOutput(false))
+}
+
+// resolveOverlap fixes a single pair, re-reading both entries since earlier fixes may have changed them.
+func resolveOverlap(o Overlap) {
+	first, err := database.GetEntry(o.First.ID)
+	if err == nil {
+		var second *Entry
+		second, err = database.GetEntry(o.Second.ID)
+		o = Overlap{First: *first, Second: *second}
+	}
+	if err != nil {
+		// Deleted while resolving an earlier pair.
+		return
+	}
+	if o.Duration() <= 0 {
+		return
+	}
+	fix, err := planOverlapFix(o, overlapsResolve, force)
+	if err != nil {
+		printOverlap(o)
+		fmt.Printf("   %s skipped: %s\n", CharError, err.Error())
+		return
+	}
+	// A new entry only gets its id once it is added, so it is printed afterwards.
+	verb := ""
+	if dryRun {
+		verb = "would "
+	}
+	if fix.delete != nil {
+		fmt.Printf("%s %serase %s\n", CharErase, verb, fix.delete.GetOutput(false))
+	}
+	if fix.update != nil {
+		fmt.Printf("%s %schange %s\n", CharInfo, verb, fix.update.GetOutput(false))
+	}
+	if dryRun {
+		if fix.add != nil {
+			fmt.Printf("%s would add %s\n", CharTrack, fix.add.GetOutput(false))
+		}
+		return
+	}
+
+	if fix.delete != nil {
+		err = database.DeleteEntry(fix.delete.ID)
+	}
+	if err == nil && fix.update != nil {
+		_, err = database.UpdateEntry(*fix.update)
+	}
+	if err == nil && fix.add != nil {
+		_, err = database.AddEntry(fix.add, false)
+	}
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+	if fix.add != nil {
+		fmt.Printf("%s add %s\n", CharTrack, fix.add.GetOutput(false))
+	}
+}
+
+// overlapFix is what resolving an overlap changes.
+type overlapFix struct {
+	update *Entry
+	add    *Entry
+	delete *Entry
+}
+
+// planOverlapFix works out how to resolve the overlap. Invoiced activities are only changed with force,
+// their hours would not match the invoice anymore otherwise.
+func planOverlapFix(o Overlap, strategy string, force bool) (overlapFix, error) {
+	first, second := o.First, o.Second
+	changed := first
+	if strategy == resolveDelete && second.ID > first.ID {
+		changed = second
+	}
+	if changed.BillingState == BillingInvoiced && !force {
+		return overlapFix{}, fmt.Errorf("activity %d is on invoice %s, use --force to change it anyway", changed.ID, changed.InvoiceNumber)
+	}
+	if strategy == resolveDelete {
+		return overlapFix{delete: &changed}, nil
+	}
+
+	beginsTogether := !first.Begin.Before(second.Begin)
+	outlasts := first.Finish.After(second.Finish)
+	switch {
+	case beginsTogether && !outlasts:
+		return overlapFix{}, errors.New("the earlier activity lies completely within the later one, use --resolve delete")
+	case beginsTogether:
+		// Only the part after the later activity is left.
+		first.Begin = second.Finish
+		first.SetDateFromBegining()
+	case outlasts && strategy == resolveTrim:
+		return overlapFix{}, errors.New("the later activity lies within the earlier one, use --resolve split")
+	case outlasts:
+		rest := first
+		rest.ID = 0
+		rest.Begin = second.Finish
+		rest.SetDateFromBegining()
+		rest.Pauses = pausesBetween(first.Pauses, rest.Begin, rest.Finish)
+		rest.Hours = rest.GetDuration()
+		first.Finish = second.Begin
+		first.Pauses = pausesBetween(first.Pauses, first.Begin, first.Finish)
+		first.Hours = first.GetDuration()
+		return overlapFix{update: &first, add: &rest}, nil
+	default:
+		first.Finish = second.Begin
+	}
+	// Pauses outside of the trimmed activity are dropped, the ones on its new bounds are cut to them.
+	first.Pauses = pausesBetween(first.Pauses, first.Begin, first.Finish)
+	first.Hours = first.GetDuration()
+	return overlapFix{update: &first}, nil
+}
+
+// pausesBetween returns the parts of the pauses that fall between from and to.
+func pausesBetween(pauses []Pause, from time.Time, to time.Time) []Pause {
+	var between []Pause
+	for _, pause := range pauses {
+		if overlap(pause.Begin, pause.Finish, from, to) <= 0 {
+			continue
+		}
+		between = append(between, Pause{Begin: maxTime(pause.Begin, from), Finish: minTime(pause.Finish, to)})
+	}
+	return between
+}
+
+func init() {
+	rootCmd.AddCommand(overlapsCmd)
+	overlapsCmd.Flags().StringVar(&overlapsResolve, "resolve", "", "Resolve every overlap: trim, split or delete")
+	overlapsCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only show what --resolve would change")
+	overlapsCmd.Flags().BoolVar(&force, "force", false, "Also change activities that are invoiced already")
+
+	var err error
+	database, err = InitDB()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+}
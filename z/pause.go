@@ -0,0 +1,95 @@
+package z
+
+import (
+	"time"
+)
+
+// Pause is a break within an entry, Finish is zero while the entry is still paused.
+type Pause struct {
+	Begin  time.Time `json:"begin"`
+	Finish time.Time `json:"finish,omitempty"`
+}
+
+func (entry *Entry) IsPaused() bool {
+	return len(entry.Pauses) > 0 && entry.Pauses[len(entry.Pauses)-1].Finish.IsZero()
+}
+
+// CurrentPause returns the pause the entry is in right now, nil if it is not paused.
+func (entry *Entry) CurrentPause() *Pause {
+	if !entry.IsPaused() {
+		return nil
+	}
+	return &entry.Pauses[len(entry.Pauses)-1]
+}
+
+// end is when the entry finished, or now while it is running.
+func (entry *Entry) end() time.Time {
+	if entry.Finish.IsZero() || entry.Finish.Before(entry.Begin) {
+		return time.Now().Truncate(0)
+	}
+	return entry.Finish
+}
+
+// ActiveDurationBetween is the time the entry was tracked between from and to, pauses not counted.
+func (entry *Entry) ActiveDurationBetween(from time.Time, to time.Time) time.Duration {
+	end := entry.end()
+	active := overlap(entry.Begin, end, from, to)
+	for _, pause := range entry.Pauses {
+		pauseFinish := pause.Finish
+		if pauseFinish.IsZero() {
+			pauseFinish = end
+		}
+		active -= overlap(pause.Begin, pauseFinish, maxTime(from, entry.Begin), minTime(to, end))
+	}
+	if active < 0 {
+		return 0
+	}
+	return active
+}
+
+// ActiveDuration is the time from Begin until Finish (or now, while running) without the pauses.
+func (entry *Entry) ActiveDuration() time.Duration {
+	return entry.ActiveDurationBetween(entry.Begin, entry.end())
+}
+
+// PausedDuration is the time the entry spent paused so far.
+func (entry *Entry) PausedDuration() time.Duration {
+	return entry.end().Sub(entry.Begin) - entry.ActiveDuration()
+}
+
+func overlap(beginA time.Time, finishA time.Time, beginB time.Time, finishB time.Time) time.Duration {
+	duration := minTime(finishA, finishB).Sub(maxTime(beginA, beginB))
+	if duration < 0 {
+		return 0
+	}
+	return duration
+}
+
+func minTime(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// IsForgotten reports if the entry has been tracked for longer than max, which usually means nobody finished it.
+func (entry *Entry) IsForgotten(max time.Duration) bool {
+	return entry.ActiveDuration() > max
+}
+
+// SuggestedCap is where a forgotten entry most likely should have finished:
+// the end of the working day it began on, or max after it began if it began after that.
+func (entry *Entry) SuggestedCap(max time.Duration) time.Time {
+	suggested := workdayEnd(entry.Begin)
+	if !suggested.After(entry.Begin) {
+		suggested = entry.Begin.Add(max)
+	}
+	return minTime(suggested, time.Now().Truncate(0))
+}
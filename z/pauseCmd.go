@@ -0,0 +1,73 @@
+package z
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause ([flags]) [id]",
+	Short: "Pause currently running activity",
+	Long:  "Pause the currently running activity, e.g. for a lunch break. The time until 'resume' is not counted.",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var err error
+		runningEntry := selectRunningEntry(args)
+		if runningEntry == nil {
+			fmt.Printf("%s no task is currently running. Can only pause a running task.\n", CharError)
+			os.Exit(1)
+		}
+		if runningEntry.IsPaused() {
+			fmt.Printf("%s the task is already paused since %s.\n\nType 'zeit resume' to continue it.\n", CharError, runningEntry.CurrentPause().Begin.Format("15:04"))
+			os.Exit(1)
+		}
+
+		pauseTime := time.Now().Truncate(0)
+		if begin != "" {
+			pauseTime, err = ParseTime(begin)
+			if err != nil {
+				fmt.Printf("%s could not parse --begin '%s'. Error: %s\n", CharError, begin, err.Error())
+				os.Exit(1)
+			}
+		}
+		earliest := runningEntry.Begin
+		if len(runningEntry.Pauses) > 0 {
+			earliest = runningEntry.Pauses[len(runningEntry.Pauses)-1].Finish
+		}
+		if pauseTime.Before(earliest) {
+			fmt.Printf("%s the pause can not begin before %s.\n", CharError, earliest.Format("2006-01-02 15:04"))
+			os.Exit(1)
+		}
+
+		err = database.PauseEntry(*runningEntry, pauseTime)
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s paused %s on %s at %s\n", CharPause,
+			color.FgLightWhite.Render(runningEntry.Task),
+			color.FgLightWhite.Render(runningEntry.Project),
+			color.FgLightWhite.Render(pauseTime.Format("15:04")))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pauseCmd)
+	pauseCmd.Flags().StringVarP(&project, "project", "p", "", "With parallel timers, pause the running activity of this project")
+	pauseCmd.Flags().StringVarP(&task, "task", "t", "", "With parallel timers, pause the running activity with this task")
+	pauseCmd.Flags().StringVarP(&begin, "begin", "b", "", "Time the pause should begin at\n\nEither in the formats 16:00 / 4:00PM \nor relative to the current time, \ne.g. -0:15 (now minus 15 minutes), +1.50 (now plus 1:30h).")
+
+	registerProjectTaskCompletion(pauseCmd, false)
+	pauseCmd.ValidArgsFunction = completeRunningEntryID
+
+	var err error
+	database, err = InitDB()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+}
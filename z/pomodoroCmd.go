@@ -0,0 +1,144 @@
+package z
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+var pomodoroWork time.Duration
+var pomodoroBreak time.Duration
+var pomodoroCycles int
+var pomodoroRecordBreaks bool
+var pomodoroBreakProject string
+
+var pomodoroCmd = &cobra.Command{
+	Use:   "pomodoro",
+	Short: "Track activity in pomodoros",
+	Long: `Track activity in pomodoros: work intervals followed by short breaks.
+
+Runs in the foreground and shows a countdown. Every work interval is added as
+a finished activity, completed ones are counted as pomodoros in 'zeit stats'.
+Ctrl-C stops the session, the interrupted interval is kept up to that moment.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		exitIfEntryRunning()
+		if task == "" {
+			fmt.Printf("%s Can not track empty task.\nPlease assign a task via --task to track\n", CharError)
+			os.Exit(1)
+		}
+		if project == "" {
+			fmt.Printf("%s Can not track empty project.\nPlease assign a project via --project\n", CharError)
+			os.Exit(1)
+		}
+		if pomodoroWork <= 0 || pomodoroBreak < 0 || pomodoroCycles < 1 {
+			fmt.Printf("%s --work and --cycles have to be positive, --break can not be negative.\n", CharError)
+			os.Exit(1)
+		}
+
+		signals, stop := notifyInterrupt()
+		defer stop()
+
+		intervalBegin := time.Now().Truncate(0)
+		for cycle := 1; cycle <= pomodoroCycles; cycle++ {
+			work := NewEntry(project, task)
+			work.Begin = intervalBegin
+			work.SetDateFromBegining()
+			work.Notes = notes
+			label := fmt.Sprintf("pomodoro %d/%d %s on %s", cycle, pomodoroCycles,
+				color.FgLightWhite.Render(task), color.FgLightWhite.Render(project))
+			intervalBegin = runPomodoroInterval(&work, true, pomodoroWork, CharTrack+" "+label, signals)
+			fmt.Print("\a")
+
+			if cycle == pomodoroCycles || pomodoroBreak == 0 {
+				continue
+			}
+			label = fmt.Sprintf("break %d/%d", cycle, pomodoroCycles-1)
+			if pomodoroRecordBreaks {
+				pause := NewEntry(pomodoroBreakProject, "Pomodoro break")
+				pause.Begin = intervalBegin
+				pause.SetDateFromBegining()
+				intervalBegin = runPomodoroInterval(&pause, false, pomodoroBreak, CharPause+" "+label, signals)
+			} else {
+				intervalBegin = runPomodoroInterval(nil, false, pomodoroBreak, CharPause+" "+label, signals)
+			}
+			fmt.Print("\a")
+		}
+		fmt.Printf("%s finished %d pomodoros\n", CharFinish, pomodoroCycles)
+	},
+}
+
+// runPomodoroInterval tracks entry (if any) for the given duration and returns when the interval ended.
+// On Ctrl-C the entry is finished at that moment and zeit exits. Only completed work intervals count as pomodoros.
+func runPomodoroInterval(entry *Entry, work bool, duration time.Duration, label string, signals chan os.Signal) time.Time {
+	if entry != nil {
+		startEntry(entry)
+	}
+	intervalBegin := time.Now().Truncate(0)
+	if entry != nil {
+		intervalBegin = entry.Begin
+	}
+	deadline := intervalBegin.Add(duration)
+
+	startLiveLine()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	interrupted := false
+	finishedAt := deadline
+countdown:
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			break
+		}
+		drawLiveLine(fmt.Sprintf("%s %s left", label, color.FgLightWhite.Render(fmtCountdown(remaining))))
+		select {
+		case <-ticker.C:
+		case <-signals:
+			interrupted = true
+			finishedAt = time.Now().Truncate(0)
+			break countdown
+		}
+	}
+	stopLiveLine()
+
+	if entry != nil {
+		entry.Finish = finishedAt
+		entry.Running = false
+		entry.Hours = entry.GetDuration()
+		entry.Pomodoro = work && !interrupted
+		err := database.AddFinishToEntry(*entry)
+		if err != nil {
+			fmt.Printf("%s could not finish the entry. Error: %s\n", CharError, err.Error())
+			os.Exit(1)
+		}
+		fmt.Print(entry.GetOutputForFinish())
+	}
+	if interrupted {
+		fmt.Printf("%s pomodoro session interrupted\n", CharInfo)
+		os.Exit(1)
+	}
+	return finishedAt
+}
+
+func init() {
+	rootCmd.AddCommand(pomodoroCmd)
+	pomodoroCmd.Flags().StringVarP(&project, "project", "p", "", "Project to be assigned")
+	pomodoroCmd.Flags().StringVarP(&task, "task", "t", "", "Task to be assigned")
+	pomodoroCmd.Flags().StringVarP(&notes, "notes", "n", "", "Activity notes")
+	pomodoroCmd.Flags().DurationVar(&pomodoroWork, "work", 25*time.Minute, "Length of a work interval")
+	pomodoroCmd.Flags().DurationVar(&pomodoroBreak, "break", 5*time.Minute, "Length of a break")
+	pomodoroCmd.Flags().IntVar(&pomodoroCycles, "cycles", 4, "Number of work intervals")
+	pomodoroCmd.Flags().BoolVar(&pomodoroRecordBreaks, "record-breaks", false, "Add the breaks as activities too")
+	pomodoroCmd.Flags().StringVar(&pomodoroBreakProject, "break-project", "Break", "Project the breaks are added to with --record-breaks")
+
+	var err error
+	database, err = InitDB()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+}
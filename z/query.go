@@ -0,0 +1,109 @@
+package z
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	OrderByBegin      = "begin"
+	OrderByBeginDesc  = "begin-desc"
+	OrderByFinish     = "finish"
+	OrderByFinishDesc = "finish-desc"
+	OrderByHours      = "hours"
+	OrderByHoursDesc  = "hours-desc"
+)
+
+var entryOrders = map[string]string{
+	OrderByBegin:      "start ASC, id ASC",
+	OrderByBeginDesc:  "start DESC, id DESC",
+	OrderByFinish:     "finish ASC, id ASC",
+	OrderByFinishDesc: "finish DESC, id DESC",
+	OrderByHours:      "seconds ASC, id ASC",
+	OrderByHoursDesc:  "seconds DESC, id DESC",
+}
+
+// EntryQuery selects entries in SQL, every zero value means 'do not filter on this'.
+// Project and task are matched like GetIdFromName does, ignoring case and anything but letters and digits.
+type EntryQuery struct {
+	Project  string
+	Task     string
+	Since    time.Time // Entries that began at or after Since
+	Until    time.Time // Entries that finished at or before Until, running ones that began before it
+	Running  bool      // Only running entries
+	Finished bool      // Only finished entries
+	Billing  string    // Only entries in this billing state, one of the Billing constants
+	IDPrefix string    // Only entries whose id begins with these digits
+	Limit    int
+	Order    string // One of the OrderBy constants, OrderByBegin if empty
+}
+
+// conditions returns the filters of the query as SQL conditions on the entries table and their arguments.
+func (query EntryQuery) conditions() ([]string, []any) {
+	var conditions []string
+	var args []any
+
+	if query.Project != "" {
+		conditions = append(conditions, "project_key = ?")
+		args = append(args, GetIdFromName(query.Project))
+	}
+	if query.Task != "" {
+		conditions = append(conditions, "task_key = ?")
+		args = append(args, GetIdFromName(query.Task))
+	}
+	if !query.Since.IsZero() {
+		conditions = append(conditions, "start >= ?")
+		args = append(args, query.Since.Unix())
+	}
+	if !query.Until.IsZero() {
+		conditions = append(conditions, "(finish <= ? OR (finish IS NULL AND start <= ?))")
+		args = append(args, query.Until.Unix(), query.Until.Unix())
+	}
+	if query.Running {
+		conditions = append(conditions, "running = 1")
+	}
+	if query.Finished {
+		conditions = append(conditions, "running = 0")
+	}
+	if query.Billing != "" {
+		conditions = append(conditions, "billing_state = ?")
+		args = append(args, query.Billing)
+	}
+	if query.IDPrefix != "" {
+		conditions = append(conditions, "CAST(id AS TEXT) LIKE ? || '%'")
+		args = append(args, query.IDPrefix)
+	}
+	return conditions, args
+}
+
+func (query EntryQuery) toSQL() (string, []any, error) {
+	conditions, args := query.conditions()
+	order := query.Order
+	if order == "" {
+		order = OrderByBegin
+	}
+	orderSQL, ok := entryOrders[order]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown order '%s'", order)
+	}
+
+	sql := `SELECT ` + entryColumns + ` FROM entries`
+	if len(conditions) > 0 {
+		sql += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	sql += ` ORDER BY ` + orderSQL
+	if query.Limit > 0 {
+		sql += ` LIMIT ?`
+		args = append(args, query.Limit)
+	}
+	return sql + ";", args, nil
+}
+
+func (db *Database) QueryEntries(query EntryQuery) ([]Entry, error) {
+	sql, args, err := query.toSQL()
+	if err != nil {
+		return nil, err
+	}
+	return db.queryEntries(sql, args...)
+}
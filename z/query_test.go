@@ -0,0 +1,190 @@
+package z
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEntryQueryToSQL(t *testing.T) {
+	since := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 8, 31, 23, 59, 59, 0, time.UTC)
+	selectEntries := `SELECT ` + entryColumns + ` FROM entries`
+
+	tests := []struct {
+		name  string
+		query EntryQuery
+		sql   string
+		args  []any
+	}{
+		{
+			name:  "everything",
+			query: EntryQuery{},
+			sql:   selectEntries + ` ORDER BY start ASC, id ASC;`,
+		},
+		{
+			name:  "project and task keys",
+			query: EntryQuery{Project: "My Project", Task: "Code-Review"},
+			sql:   selectEntries + ` WHERE project_key = ? AND task_key = ? ORDER BY start ASC, id ASC;`,
+			args:  []any{"myproject", "codereview"},
+		},
+		{
+			name:  "since and until",
+			query: EntryQuery{Since: since, Until: until},
+			sql:   selectEntries + ` WHERE start >= ? AND (finish <= ? OR (finish IS NULL AND start <= ?)) ORDER BY start ASC, id ASC;`,
+			args:  []any{since.Unix(), until.Unix(), until.Unix()},
+		},
+		{
+			name:  "running",
+			query: EntryQuery{Running: true},
+			sql:   selectEntries + ` WHERE running = 1 ORDER BY start ASC, id ASC;`,
+		},
+		{
+			name:  "finished and unbilled",
+			query: EntryQuery{Finished: true, Billing: BillingUnbilled},
+			sql:   selectEntries + ` WHERE running = 0 AND billing_state = ? ORDER BY start ASC, id ASC;`,
+			args:  []any{BillingUnbilled},
+		},
+		{
+			name:  "id prefix",
+			query: EntryQuery{IDPrefix: "12"},
+			sql:   selectEntries + ` WHERE CAST(id AS TEXT) LIKE ? || '%' ORDER BY start ASC, id ASC;`,
+			args:  []any{"12"},
+		},
+		{
+			name:  "order and limit",
+			query: EntryQuery{Project: "zeit", Order: OrderByHoursDesc, Limit: 10},
+			sql:   selectEntries + ` WHERE project_key = ? ORDER BY seconds DESC, id DESC LIMIT ?;`,
+			args:  []any{"zeit", 10},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sql, args, err := test.query.toSQL()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != test.sql {
+				t.Errorf("sql is\n%s\nwant\n%s", sql, test.sql)
+			}
+			if !reflect.DeepEqual(args, test.args) {
+				t.Errorf("args are %v, want %v", args, test.args)
+			}
+		})
+	}
+}
+
+func TestEntryQueryToSQLUnknownOrder(t *testing.T) {
+	_, _, err := EntryQuery{Order: "duration"}.toSQL()
+	if err == nil {
+		t.Fatal("toSQL accepted an unknown order")
+	}
+}
+
+func TestQueryEntries(t *testing.T) {
+	db := newTestDatabase(t)
+	begin := time.Date(2024, 8, 1, 9, 0, 0, 0, time.UTC)
+	seedEntries(t, db, begin, 35)
+
+	entries, err := db.QueryEntries(EntryQuery{
+		Project: "project 1",
+		Since:   begin.Add(2 * time.Hour),
+		Until:   begin.Add(31*time.Hour + 15*time.Minute),
+		Order:   OrderByBeginDesc,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	// 'Project 1' has the entries 2, 12, 22 and 32: 2 begins before Since and 32 finishes after Until.
+	if want := []int64{22, 12}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("QueryEntries returned entries %v, want %v", ids, want)
+	}
+}
+
+// seedEntries adds count finished entries of half an hour, one per hour from begin on,
+// alternating between ten projects and twenty tasks.
+func seedEntries(tb testing.TB, db *Database, begin time.Time, count int) {
+	tb.Helper()
+	ctx := context.Background()
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer tx.Rollback()
+	for i := 0; i < count; i++ {
+		entry := Entry{
+			Project: fmt.Sprintf("Project %d", i%10),
+			Task:    fmt.Sprintf("Task %d", i%20),
+			Begin:   begin.Add(time.Duration(i) * time.Hour),
+		}
+		entry.Finish = entry.Begin.Add(30 * time.Minute)
+		entry.SetDateFromBegining()
+		entry.Hours = entry.GetDuration()
+		err = insertEntry(ctx, tx, &entry, false)
+		if err != nil {
+			tb.Fatal(err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func BenchmarkQueryEntries(b *testing.B) {
+	if testing.Short() {
+		b.Skip("seeding 500k entries takes a while")
+	}
+	db := newTestDatabase(b)
+	begin := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
+	seedEntries(b, db, begin, 500000)
+
+	month := begin.AddDate(2, 0, 0)
+	queries := []struct {
+		name  string
+		query EntryQuery
+	}{
+		{"project", EntryQuery{Project: "Project 3"}},
+		{"project and task", EntryQuery{Project: "Project 3", Task: "Task 13"}},
+		{"since until", EntryQuery{Since: month, Until: month.AddDate(0, 1, 0)}},
+		{"project since until", EntryQuery{Project: "Project 3", Since: month, Until: month.AddDate(0, 1, 0)}},
+		{"latest", EntryQuery{Order: OrderByBeginDesc, Limit: 20}},
+		{"project latest", EntryQuery{Project: "Project 3", Order: OrderByBeginDesc, Limit: 20}},
+	}
+	for _, query := range queries {
+		b.Run(query.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, err := db.QueryEntries(query.query)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestGetPomodorosPerDayFilters(t *testing.T) {
+	db := newTestDatabase(t)
+	begin := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+	seedEntries(t, db, begin, 48)
+	_, err := db.DB.Exec(`UPDATE entries SET pomodoro = 1;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 'Project 1' has the entries at 1:00, 11:00 and 21:00 of the first day and at 7:00 and 17:00 of the second.
+	pomodoros, err := db.GetPomodorosPerDay(EntryQuery{Project: "Project 1", Until: begin.Add(24 * time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PomodorosOfDay{{Date: "01-08-2024", Count: 3}}
+	if !reflect.DeepEqual(pomodoros, want) {
+		t.Errorf("GetPomodorosPerDay returned %+v, want %+v", pomodoros, want)
+	}
+}
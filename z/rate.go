@@ -0,0 +1,130 @@
+package z
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TaskRate is an hourly rate for a single task, it wins over the rate of the project.
+type TaskRate struct {
+	Project string
+	Task    string
+	Rate    decimal.Decimal
+}
+
+// Rates are the hourly rates of a project and its tasks.
+type Rates struct {
+	Project decimal.Decimal
+	// Tasks are the task rates by the key of the task.
+	Tasks map[string]decimal.Decimal
+}
+
+// For returns the rate of task, false if neither the task nor the project has one.
+func (rates Rates) For(task string) (decimal.Decimal, bool) {
+	if rate, ok := rates.Tasks[GetIdFromName(task)]; ok {
+		return rate, true
+	}
+	return rates.Project, rates.Project.IsPositive()
+}
+
+func (db *Database) SetProjectRate(name string, rate decimal.Decimal) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err := db.DB.ExecContext(ctx, `INSERT INTO projects(key, name, rate) VALUES(?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET name = excluded.name, rate = excluded.rate;`,
+		GetIdFromName(name), name, rate.String())
+	return err
+}
+
+func (db *Database) SetTaskRate(rate TaskRate) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err := db.DB.ExecContext(ctx, `INSERT INTO task_rates(project_key, task_key, project, task, rate) VALUES(?, ?, ?, ?, ?)
+		ON CONFLICT(project_key, task_key) DO UPDATE SET project = excluded.project, task = excluded.task, rate = excluded.rate;`,
+		GetIdFromName(rate.Project), GetIdFromName(rate.Task), rate.Project, rate.Task, rate.Rate.String())
+	return err
+}
+
+// GetTaskRates returns the task rates of the project, of all projects if it is empty.
+func (db *Database) GetTaskRates(project string) ([]TaskRate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	rows, err := db.DB.QueryContext(ctx, `SELECT project, task, rate FROM task_rates WHERE ? = '' OR project_key = ? ORDER BY project, task;`,
+		project, GetIdFromName(project))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var rates []TaskRate
+	for rows.Next() {
+		var rate TaskRate
+		var value string
+		err := rows.Scan(&rate.Project, &rate.Task, &value)
+		if err != nil {
+			return nil, err
+		}
+		rate.Rate, err = decimal.NewFromString(value)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
+
+// GetRates returns the rates of the project and its tasks.
+func (db *Database) GetRates(project string) (Rates, error) {
+	rates := Rates{Project: decimal.Zero, Tasks: map[string]decimal.Decimal{}}
+	rateProject, err := db.GetProject(project)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return rates, err
+	}
+	if rateProject != nil {
+		rates.Project = rateProject.Rate
+	}
+	taskRates, err := db.GetTaskRates(project)
+	if err != nil {
+		return rates, err
+	}
+	for _, taskRate := range taskRates {
+		rates.Tasks[GetIdFromName(taskRate.Task)] = taskRate.Rate
+	}
+	return rates, nil
+}
+
+// RemoveProjectRate removes the rate of the project, sql.ErrNoRows if it has none.
+func (db *Database) RemoveProjectRate(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	result, err := db.DB.ExecContext(ctx, `UPDATE projects SET rate = NULL WHERE key = ? AND rate IS NOT NULL;`, GetIdFromName(name))
+	if err != nil {
+		return err
+	}
+	return errIfNoneAffected(result)
+}
+
+// RemoveTaskRate removes the rate of the task, sql.ErrNoRows if it has none.
+func (db *Database) RemoveTaskRate(project string, task string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	result, err := db.DB.ExecContext(ctx, `DELETE FROM task_rates WHERE project_key = ? AND task_key = ?;`, GetIdFromName(project), GetIdFromName(task))
+	if err != nil {
+		return err
+	}
+	return errIfNoneAffected(result)
+}
+
+func errIfNoneAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package z
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/gookit/color"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var rateValue string
+
+var rateCmd = &cobra.Command{
+	Use:   "rate",
+	Short: "Manage hourly rates",
+	Long: `Manage the hourly rates 'zeit invoice' bills projects at.
+
+A rate is set for a project, and optionally for single tasks of it,
+which then win over the rate of the project.`,
+}
+
+var rateSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set the hourly rate of a project or task",
+	Run: func(cmd *cobra.Command, args []string) {
+		if project == "" {
+			fmt.Printf("%s Please give the project via --project\n", CharError)
+			os.Exit(1)
+		}
+		rate, err := decimal.NewFromString(rateValue)
+		if err != nil || !rate.IsPositive() {
+			fmt.Printf("%s --rate has to be an amount above 0, e.g. 95 or 87.50\n", CharError)
+			os.Exit(1)
+		}
+		if task != "" {
+			err = database.SetTaskRate(TaskRate{Project: project, Task: task, Rate: rate})
+		} else {
+			err = database.SetProjectRate(project, rate)
+		}
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s %s bills %s per hour\n", CharInfo, getOutputForRateOf(project, task), color.FgLightWhite.Render(rate.StringFixed(2)))
+	},
+}
+
+var rateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the hourly rates",
+	Run: func(cmd *cobra.Command, args []string) {
+		projects, err := database.GetProjects()
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		taskRates, err := database.GetTaskRates(project)
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		listed := 0
+		for _, rateProject := range projects {
+			if project != "" && GetIdFromName(rateProject.Name) != GetIdFromName(project) {
+				continue
+			}
+			if rateProject.Rate.IsPositive() {
+				fmt.Printf("%s %s %s/h\n", CharMore, color.FgLightWhite.Render(rateProject.Name), rateProject.Rate.StringFixed(2))
+				listed++
+			}
+		}
+		for _, taskRate := range taskRates {
+			fmt.Printf("%s %s %s/h\n", CharMore, getOutputForRateOf(taskRate.Project, taskRate.Task), taskRate.Rate.StringFixed(2))
+			listed++
+		}
+		if listed == 0 {
+			fmt.Printf("%s no rates yet, set one with 'zeit rate set'\n", CharInfo)
+		}
+	},
+}
+
+var rateRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove the hourly rate of a project or task",
+	Run: func(cmd *cobra.Command, args []string) {
+		if project == "" {
+			fmt.Printf("%s Please give the project via --project\n", CharError)
+			os.Exit(1)
+		}
+		var err error
+		if task != "" {
+			err = database.RemoveTaskRate(project, task)
+		} else {
+			err = database.RemoveProjectRate(project)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			fmt.Printf("%s %s has no rate.\n", CharError, getOutputForRateOf(project, task))
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s removed the rate of %s\n", CharErase, getOutputForRateOf(project, task))
+	},
+}
+
+func getOutputForRateOf(project string, task string) string {
+	if task == "" {
+		return color.FgLightWhite.Render(project)
+	}
+	return fmt.Sprintf("%s on %s", color.FgLightWhite.Render(task), color.FgLightWhite.Render(project))
+}
+
+func init() {
+	rootCmd.AddCommand(rateCmd)
+	rateCmd.AddCommand(rateSetCmd)
+	rateCmd.AddCommand(rateListCmd)
+	rateCmd.AddCommand(rateRemoveCmd)
+	rateSetCmd.Flags().StringVarP(&project, "project", "p", "", "Project to set the rate for")
+	rateSetCmd.Flags().StringVarP(&task, "task", "t", "", "Task to set the rate for, instead of the whole project")
+	rateSetCmd.Flags().StringVar(&rateValue, "rate", "", "Amount billed per hour")
+	rateListCmd.Flags().StringVarP(&project, "project", "p", "", "Only list the rates of this project")
+	rateRemoveCmd.Flags().StringVarP(&project, "project", "p", "", "Project to remove the rate of")
+	rateRemoveCmd.Flags().StringVarP(&task, "task", "t", "", "Task to remove the rate of, instead of the one of the whole project")
+
+	registerProjectTaskCompletion(rateSetCmd, false)
+	registerProjectTaskCompletion(rateListCmd, false)
+	registerProjectTaskCompletion(rateRemoveCmd, false)
+
+	var err error
+	database, err = InitDB()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,338 @@
+package z
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Weekdays is a set of days of the week, bit n stands for time.Weekday(n).
+type Weekdays int
+
+const AllWeekdays Weekdays = 1<<7 - 1
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// ParseWeekdays reads a set of days like 'mon,wed,fri', 'mon-fri', 'weekdays', 'weekend' or 'daily'.
+func ParseWeekdays(value string) (Weekdays, error) {
+	var days Weekdays
+	for _, part := range strings.Split(strings.ToLower(value), ",") {
+		part = strings.TrimSpace(part)
+		switch part {
+		case "daily", "every day":
+			days |= AllWeekdays
+			continue
+		case "weekdays":
+			part = "mon-fri"
+		case "weekend":
+			part = "sat-sun"
+		}
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := parseWeekday(from)
+		if err != nil {
+			return 0, err
+		}
+		last := first
+		if isRange {
+			last, err = parseWeekday(to)
+			if err != nil {
+				return 0, err
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			days |= 1 << day
+			if day == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// parseWeekday reads a lower case day like 'monday', 'mon' or 'mo'.
+func parseWeekday(value string) (time.Weekday, error) {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 {
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.HasPrefix(strings.ToLower(day.String()), value) {
+				return day, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unknown day of the week '%s'", value)
+}
+
+func (days Weekdays) Contains(day time.Weekday) bool {
+	return days&(1<<day) != 0
+}
+
+func (days Weekdays) String() string {
+	var names []string
+	// Weeks start on monday.
+	for i := 1; i <= 7; i++ {
+		if days.Contains(time.Weekday(i % 7)) {
+			names = append(names, weekdayNames[i%7])
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// Recurring is an entry that is added automatically on the given days of the week, starting with Since.
+type Recurring struct {
+	ID       int64
+	Weekdays Weekdays
+	At       string // 15:04
+	Duration time.Duration
+	Project  string
+	Task     string
+	Notes    string
+	Since    time.Time
+	// LastApplied is the last day up to which every entry has been added, zero if none was yet.
+	LastApplied time.Time
+}
+
+// dayFormat is how days are stored for recurring entries and holidays.
+const dayFormat = "2006-01-02"
+
+// EntryOn is the entry the schedule adds on the given day.
+func (recurring *Recurring) EntryOn(day time.Time) (Entry, error) {
+	at, err := time.Parse("15:04", recurring.At)
+	if err != nil {
+		return Entry{}, err
+	}
+	entry := NewEntry(recurring.Project, recurring.Task)
+	entry.Notes = recurring.Notes
+	entry.Begin = time.Date(day.Year(), day.Month(), day.Day(), at.Hour(), at.Minute(), 0, 0, time.Local)
+	entry.Finish = entry.Begin.Add(recurring.Duration)
+	entry.SetDateFromBegining()
+	entry.Hours = entry.GetDuration()
+	return entry, nil
+}
+
+func (db *Database) AddRecurring(recurring *Recurring) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	result, err := db.DB.ExecContext(ctx, `INSERT INTO recurring(weekdays, at, seconds, project, task, notes, since) VALUES(?, ?, ?, ?, ?, ?, ?);`,
+		recurring.Weekdays, recurring.At, int64(recurring.Duration.Seconds()), recurring.Project, recurring.Task, recurring.Notes, recurring.Since.Format(dayFormat))
+	if err != nil {
+		return err
+	}
+	recurring.ID, err = result.LastInsertId()
+	return err
+}
+
+func (db *Database) GetRecurring() ([]Recurring, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	return getRecurring(ctx, db.DB)
+}
+
+func getRecurring(ctx context.Context, tx execer) ([]Recurring, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, weekdays, at, seconds, project, task, notes, since, last_applied FROM recurring ORDER BY id;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var recurring []Recurring
+	for rows.Next() {
+		var r Recurring
+		var seconds int64
+		var since string
+		var lastApplied sql.NullString
+		err := rows.Scan(&r.ID, &r.Weekdays, &r.At, &seconds, &r.Project, &r.Task, &r.Notes, &since, &lastApplied)
+		if err != nil {
+			return nil, err
+		}
+		r.Duration = time.Duration(seconds) * time.Second
+		r.Since, err = time.ParseInLocation(dayFormat, since, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		if lastApplied.Valid {
+			r.LastApplied, err = time.ParseInLocation(dayFormat, lastApplied.String, time.Local)
+			if err != nil {
+				return nil, err
+			}
+		}
+		recurring = append(recurring, r)
+	}
+	return recurring, rows.Err()
+}
+
+// DeleteRecurring stops the schedule, entries that were added already are kept. sql.ErrNoRows if there is none.
+func (db *Database) DeleteRecurring(id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	result, err := tx.ExecContext(ctx, `DELETE FROM recurring WHERE id = ?;`, id)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return sql.ErrNoRows
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM recurring_runs WHERE recurring_id = ?;`, id)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Holiday is a day on which no recurring entries are added.
+type Holiday struct {
+	Day  time.Time
+	Name string
+}
+
+func (db *Database) AddHoliday(holiday Holiday) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err := db.DB.ExecContext(ctx, `INSERT OR REPLACE INTO holidays(day, name) VALUES(?, ?);`, holiday.Day.Format(dayFormat), holiday.Name)
+	return err
+}
+
+func (db *Database) GetHolidays() ([]Holiday, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	return getHolidays(ctx, db.DB)
+}
+
+func getHolidays(ctx context.Context, tx execer) ([]Holiday, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT day, name FROM holidays ORDER BY day;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var holidays []Holiday
+	for rows.Next() {
+		var day string
+		var holiday Holiday
+		err := rows.Scan(&day, &holiday.Name)
+		if err != nil {
+			return nil, err
+		}
+		holiday.Day, err = time.ParseInLocation(dayFormat, day, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		holidays = append(holidays, holiday)
+	}
+	return holidays, rows.Err()
+}
+
+// DeleteHoliday removes the holiday on the given day, sql.ErrNoRows if there is none.
+func (db *Database) DeleteHoliday(day time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	result, err := db.DB.ExecContext(ctx, `DELETE FROM holidays WHERE day = ?;`, day.Format(dayFormat))
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ApplyRecurring adds every recurring entry that finished until then and was not added yet.
+// Days are remembered in 'recurring_runs' once they are handled, so an entry is never added twice,
+// not even after it was erased. Entries rejected for overlapping others are returned in skipped.
+func (db *Database) ApplyRecurring(until time.Time) (added []Entry, skipped []Entry, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	schedules, err := getRecurring(ctx, tx)
+	if err != nil || len(schedules) == 0 {
+		return nil, nil, err
+	}
+	holidays, err := getHolidays(ctx, tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	isHoliday := make(map[string]bool, len(holidays))
+	for _, holiday := range holidays {
+		isHoliday[holiday.Day.Format(dayFormat)] = true
+	}
+
+	for _, recurring := range schedules {
+		day := recurring.Since
+		if !recurring.LastApplied.IsZero() {
+			day = recurring.LastApplied.AddDate(0, 0, 1)
+		}
+		var lastApplied time.Time
+		for ; !day.After(until); day = day.AddDate(0, 0, 1) {
+			if !recurring.Weekdays.Contains(day.Weekday()) || isHoliday[day.Format(dayFormat)] {
+				lastApplied = day
+				continue
+			}
+			entry, err := recurring.EntryOn(day)
+			if err != nil {
+				return nil, nil, err
+			}
+			if entry.Finish.After(until) {
+				break
+			}
+			lastApplied = day
+			result, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO recurring_runs(recurring_id, day) VALUES(?, ?);`,
+				recurring.ID, day.Format(dayFormat))
+			if err != nil {
+				return nil, nil, err
+			}
+			inserted, err := result.RowsAffected()
+			if err != nil {
+				return nil, nil, err
+			}
+			if inserted == 0 {
+				// Added before already.
+				continue
+			}
+			_, err = checkOverlaps(ctx, tx, entry)
+			var overlapErr *OverlapError
+			if errors.As(err, &overlapErr) {
+				skipped = append(skipped, entry)
+				continue
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			err = insertEntry(ctx, tx, &entry, false)
+			if err != nil {
+				return nil, nil, err
+			}
+			_, err = tx.ExecContext(ctx, `UPDATE recurring_runs SET entry_id = ? WHERE recurring_id = ? AND day = ?;`,
+				entry.ID, recurring.ID, day.Format(dayFormat))
+			if err != nil {
+				return nil, nil, err
+			}
+			added = append(added, entry)
+		}
+		if lastApplied.IsZero() {
+			continue
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE recurring SET last_applied = ? WHERE id = ?;`, lastApplied.Format(dayFormat), recurring.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return added, skipped, tx.Commit()
+}
@@ -0,0 +1,310 @@
+package z
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/jinzhu/now"
+	"github.com/spf13/cobra"
+)
+
+var recurringDays string
+var recurringAt string
+var recurringDuration time.Duration
+var recurringUntil string
+
+var recurringCmd = &cobra.Command{
+	Use:   "recurring",
+	Short: "Manage recurring activities",
+	Long: `Manage activities that recur on fixed days, like a daily standup.
+
+Recurring activities are added automatically whenever zeit runs, once they are over.
+Each one is only ever added once per day, even if it is erased afterwards,
+and never on holidays (see 'zeit holiday').`,
+}
+
+var recurringAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a recurring activity",
+	Long: `Add an activity that recurs on the given days of the week.
+
+--days takes a list like 'mon,wed,fri', ranges like 'mon-fri'
+or one of 'weekdays', 'weekend' and 'daily'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if project == "" || task == "" {
+			fmt.Printf("%s a recurring activity needs a project and a task.\nPlease assign them via --project and --task\n", CharError)
+			os.Exit(1)
+		}
+		days, err := ParseWeekdays(recurringDays)
+		if err != nil {
+			fmt.Printf("%s could not parse --days '%s'. Error: %s\n", CharError, recurringDays, err.Error())
+			os.Exit(1)
+		}
+		at, err := time.Parse("15:04", recurringAt)
+		if err != nil {
+			fmt.Printf("%s --at has to be a time like 9:30 or 14:00.\n", CharError)
+			os.Exit(1)
+		}
+		if recurringDuration < time.Minute {
+			fmt.Printf("%s --duration has to be at least a minute, e.g. 15m or 1h30m.\n", CharError)
+			os.Exit(1)
+		}
+		sinceDay := now.BeginningOfDay()
+		if since != "" {
+			sinceDay, err = parseDay(since)
+			if err != nil {
+				fmt.Printf("%s could not parse --since '%s'. Error: %s\n", CharError, since, err.Error())
+				os.Exit(1)
+			}
+		}
+
+		recurring := Recurring{
+			Weekdays: days,
+			At:       at.Format("15:04"),
+			Duration: recurringDuration,
+			Project:  project,
+			Task:     task,
+			Notes:    notes,
+			Since:    sinceDay,
+		}
+		err = database.AddRecurring(&recurring)
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s added %s\n", CharInfo, getOutputForRecurring(recurring))
+
+		// Catch up right away if --since lies in the past.
+		added, skipped, err := database.ApplyRecurring(time.Now())
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		for _, entry := range added {
+			fmt.Printf("%s added %s\n", CharTrack, entry.GetOutput(false))
+		}
+		printSkippedRecurring(skipped)
+	},
+}
+
+var recurringListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recurring activities",
+	Run: func(cmd *cobra.Command, args []string) {
+		schedules, err := database.GetRecurring()
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		if len(schedules) == 0 {
+			fmt.Printf("%s no recurring activities yet, add one with 'zeit recurring add'\n", CharInfo)
+			return
+		}
+		for _, recurring := range schedules {
+			fmt.Printf("%s\n", getOutputForRecurring(recurring))
+		}
+	},
+}
+
+var recurringRemoveCmd = &cobra.Command{
+	Use:   "remove [id]",
+	Short: "Remove a recurring activity",
+	Long:  "Stop adding a recurring activity. The activities it added already are kept.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			fmt.Printf("%s %s", CharError, "Please provide a valid number")
+			os.Exit(1)
+		}
+		err = database.DeleteRecurring(id)
+		if errors.Is(err, sql.ErrNoRows) {
+			fmt.Printf("%s there is no recurring activity %d.\n", CharError, id)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s removed recurring activity %s\n", CharErase, color.FgLightWhite.Render(id))
+	},
+}
+
+var recurringApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Add the recurring activities up to a day",
+	Long:  "Add all recurring activities up to and including the day given by --until, which may lie in the future.",
+	Run: func(cmd *cobra.Command, args []string) {
+		untilDay, err := parseDay(recurringUntil)
+		if err != nil {
+			fmt.Printf("%s could not parse --until '%s'. Error: %s\n", CharError, recurringUntil, err.Error())
+			os.Exit(1)
+		}
+		added, skipped, err := database.ApplyRecurring(now.With(untilDay).EndOfDay())
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		for _, entry := range added {
+			fmt.Printf("%s added %s\n", CharTrack, entry.GetOutput(false))
+		}
+		printSkippedRecurring(skipped)
+		if len(added) == 0 && len(skipped) == 0 {
+			fmt.Printf("%s all recurring activities are added already\n", CharInfo)
+		}
+	},
+}
+
+func getOutputForRecurring(recurring Recurring) string {
+	return fmt.Sprintf("%s %s on %s every %s at %s for %sh since %s",
+		color.FgGray.Render(recurring.ID),
+		color.FgLightWhite.Render(recurring.Task),
+		color.FgLightWhite.Render(recurring.Project),
+		color.FgLightWhite.Render(recurring.Weekdays.String()),
+		color.FgLightWhite.Render(recurring.At),
+		color.FgLightWhite.Render(fmtDuration(recurring.Duration)),
+		recurring.Since.Format(dayFormat))
+}
+
+func printSkippedRecurring(skipped []Entry) {
+	for _, entry := range skipped {
+		fmt.Printf("%s skipped recurring %s on %s at %s, it overlaps other activities\n", CharError,
+			color.FgLightWhite.Render(entry.Task),
+			color.FgLightWhite.Render(entry.Project),
+			entry.Begin.Format("2006-01-02 15:04"))
+	}
+}
+
+// applyRecurring adds the recurring activities that are over by now.
+func applyRecurring() {
+	// Without the tables there is nothing to apply yet, e.g. with 'ZEIT_AUTO_MIGRATE=false'.
+	pending, err := database.PendingMigrations()
+	if err != nil || len(pending) > 0 {
+		return
+	}
+	_, skipped, err := database.ApplyRecurring(time.Now())
+	if err != nil {
+		fmt.Printf("%s could not add the recurring activities. Error: %s\n", CharError, err.Error())
+		return
+	}
+	printSkippedRecurring(skipped)
+}
+
+// parseDay reads a day like 'today', 'yesterday', 'tomorrow' or '2024-12-24' and returns its beginning.
+func parseDay(value string) (time.Time, error) {
+	today := now.BeginningOfDay()
+	switch strings.ToLower(value) {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+	day, err := now.Parse(value)
+	if err != nil {
+		return day, err
+	}
+	return now.With(day).BeginningOfDay(), nil
+}
+
+var holidayCmd = &cobra.Command{
+	Use:   "holiday",
+	Short: "Manage holidays",
+	Long:  "Manage days on which no recurring activities are added.",
+}
+
+var holidayAddCmd = &cobra.Command{
+	Use:   "add [day] ([name])",
+	Short: "Add a holiday",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		day, err := parseDay(args[0])
+		if err != nil {
+			fmt.Printf("%s could not parse the day '%s'. Error: %s\n", CharError, args[0], err.Error())
+			os.Exit(1)
+		}
+		holiday := Holiday{Day: day}
+		if len(args) > 1 {
+			holiday.Name = args[1]
+		}
+		err = database.AddHoliday(holiday)
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s added holiday %s %s\n", CharInfo, color.FgLightWhite.Render(day.Format(dayFormat)), holiday.Name)
+	},
+}
+
+var holidayListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List holidays",
+	Run: func(cmd *cobra.Command, args []string) {
+		holidays, err := database.GetHolidays()
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		for _, holiday := range holidays {
+			fmt.Printf("%s %s %s\n", CharMore, color.FgLightWhite.Render(holiday.Day.Format(dayFormat)), holiday.Name)
+		}
+	},
+}
+
+var holidayRemoveCmd = &cobra.Command{
+	Use:   "remove [day]",
+	Short: "Remove a holiday",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		day, err := parseDay(args[0])
+		if err != nil {
+			fmt.Printf("%s could not parse the day '%s'. Error: %s\n", CharError, args[0], err.Error())
+			os.Exit(1)
+		}
+		err = database.DeleteHoliday(day)
+		if errors.Is(err, sql.ErrNoRows) {
+			fmt.Printf("%s %s is no holiday.\n", CharError, day.Format(dayFormat))
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s removed holiday %s\n", CharErase, color.FgLightWhite.Render(day.Format(dayFormat)))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(recurringCmd)
+	recurringCmd.AddCommand(recurringAddCmd)
+	recurringCmd.AddCommand(recurringListCmd)
+	recurringCmd.AddCommand(recurringRemoveCmd)
+	recurringCmd.AddCommand(recurringApplyCmd)
+	rootCmd.AddCommand(holidayCmd)
+	holidayCmd.AddCommand(holidayAddCmd)
+	holidayCmd.AddCommand(holidayListCmd)
+	holidayCmd.AddCommand(holidayRemoveCmd)
+
+	recurringAddCmd.Flags().StringVarP(&project, "project", "p", "", "Project to be assigned")
+	recurringAddCmd.Flags().StringVarP(&task, "task", "t", "", "Task to be assigned")
+	recurringAddCmd.Flags().StringVarP(&notes, "notes", "n", "", "Activity notes")
+	recurringAddCmd.Flags().StringVar(&recurringDays, "days", "weekdays", "Days of the week the activity recurs on, e.g. mon-fri or mon,thu")
+	recurringAddCmd.Flags().StringVar(&recurringAt, "at", "", "Time the activity begins at, e.g. 9:30")
+	recurringAddCmd.Flags().DurationVar(&recurringDuration, "duration", 0, "How long the activity takes, e.g. 15m")
+	recurringAddCmd.Flags().StringVar(&since, "since", "", "First day the activity recurs on, default today")
+	recurringApplyCmd.Flags().StringVar(&recurringUntil, "until", "today", "Last day to add the recurring activities for, e.g. today or 2024-12-31")
+
+	var err error
+	database, err = InitDB()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,123 @@
+package z
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+var repoCmd = &cobra.Command{
+	Use:   "repo",
+	Short: "Manage git repositories of projects",
+	Long: `Manage the local git repositories the work on a project is committed to.
+
+The commits you authored in them while an activity was tracked are shown by
+'zeit entry <id> --commits' and 'zeit list --with-commits',
+'zeit finish --attach-commits' appends them to the notes.`,
+}
+
+var repoAddCmd = &cobra.Command{
+	Use:   "add [path]",
+	Short: "Map a git repository to a project",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if project == "" {
+			fmt.Printf("%s Can not add a repository without a project.\nPlease assign a project via --project\n", CharError)
+			os.Exit(1)
+		}
+		path, err := filepath.Abs(args[0])
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		path, err = GitRepoRoot(path)
+		if err != nil {
+			fmt.Printf("%s %s is no git repository.\n", CharError, args[0])
+			os.Exit(1)
+		}
+		repo := ProjectRepo{Project: project, Path: path}
+		err = database.AddProjectRepo(repo)
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s added %s\n", CharInfo, getOutputForProjectRepo(repo))
+	},
+}
+
+var repoListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the repositories of projects",
+	Run: func(cmd *cobra.Command, args []string) {
+		repos, err := database.GetProjectRepos(project)
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		if len(repos) == 0 {
+			fmt.Printf("%s no repositories yet, add one with 'zeit repo add'\n", CharInfo)
+			return
+		}
+		for _, repo := range repos {
+			fmt.Printf("%s %s\n", CharMore, getOutputForProjectRepo(repo))
+		}
+	},
+}
+
+var repoRemoveCmd = &cobra.Command{
+	Use:   "remove [path]",
+	Short: "Remove a repository from a project",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if project == "" {
+			fmt.Printf("%s Please give the project to remove the repository from via --project\n", CharError)
+			os.Exit(1)
+		}
+		path, err := filepath.Abs(args[0])
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		repo := ProjectRepo{Project: project, Path: path}
+		err = database.DeleteProjectRepo(repo)
+		if errors.Is(err, sql.ErrNoRows) {
+			fmt.Printf("%s %s is no repository of %s.\n", CharError, path, project)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s removed %s\n", CharErase, getOutputForProjectRepo(repo))
+	},
+}
+
+func getOutputForProjectRepo(repo ProjectRepo) string {
+	return fmt.Sprintf("%s: %s", color.FgLightWhite.Render(repo.Project), repo.Path)
+}
+
+func init() {
+	rootCmd.AddCommand(repoCmd)
+	repoCmd.AddCommand(repoAddCmd)
+	repoCmd.AddCommand(repoListCmd)
+	repoCmd.AddCommand(repoRemoveCmd)
+	repoAddCmd.Flags().StringVarP(&project, "project", "p", "", "Project the repository belongs to")
+	repoListCmd.Flags().StringVarP(&project, "project", "p", "", "Only list the repositories of this project")
+	repoRemoveCmd.Flags().StringVarP(&project, "project", "p", "", "Project the repository belongs to")
+
+	registerProjectTaskCompletion(repoAddCmd, false)
+	registerProjectTaskCompletion(repoListCmd, false)
+	registerProjectTaskCompletion(repoRemoveCmd, false)
+
+	var err error
+	database, err = InitDB()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+}
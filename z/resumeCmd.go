@@ -0,0 +1,70 @@
+package z
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume ([flags]) [id]",
+	Short: "Resume paused activity",
+	Long:  "Resume the currently running activity after it was paused with 'pause'.",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var err error
+		runningEntry := selectRunningEntry(args)
+		if runningEntry == nil {
+			fmt.Printf("%s no task is currently running. Can only resume a paused task.\n", CharError)
+			os.Exit(1)
+		}
+		pause := runningEntry.CurrentPause()
+		if pause == nil {
+			fmt.Printf("%s the task is not paused.\n", CharError)
+			os.Exit(1)
+		}
+
+		resumeTime := time.Now().Truncate(0)
+		if finish != "" {
+			resumeTime, err = ParseTime(finish)
+			if err != nil {
+				fmt.Printf("%s could not parse --finish '%s'. Error: %s\n", CharError, finish, err.Error())
+				os.Exit(1)
+			}
+		}
+		if !pause.Begin.Before(resumeTime) {
+			fmt.Printf("%s the pause has to finish after it began at %s.\n", CharError, pause.Begin.Format("2006-01-02 15:04"))
+			os.Exit(1)
+		}
+
+		err = database.ResumeEntry(*runningEntry, resumeTime)
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s resumed %s on %s after a pause of %sh\n", CharTrack,
+			color.FgLightWhite.Render(runningEntry.Task),
+			color.FgLightWhite.Render(runningEntry.Project),
+			color.FgLightWhite.Render(fmtDuration(resumeTime.Sub(pause.Begin))))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(resumeCmd)
+	resumeCmd.Flags().StringVarP(&project, "project", "p", "", "With parallel timers, resume the running activity of this project")
+	resumeCmd.Flags().StringVarP(&task, "task", "t", "", "With parallel timers, resume the running activity with this task")
+	resumeCmd.Flags().StringVarP(&finish, "finish", "s", "", "Time the pause should finish at\n\nEither in the formats 16:00 / 4:00PM \nor relative to the current time, \ne.g. -0:15 (now minus 15 minutes), +1.50 (now plus 1:30h).")
+
+	registerProjectTaskCompletion(resumeCmd, false)
+	resumeCmd.ValidArgsFunction = completeRunningEntryID
+
+	var err error
+	database, err = InitDB()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+}
@@ -8,7 +8,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var database *Database
+var database Store
 
 var begin string
 var finish string
@@ -20,6 +20,7 @@ var exportHours bool
 var fileName string
 var importFile string
 var exportAllFields bool
+var exportUnbilled bool
 var sorting string
 var verbose bool
 
@@ -34,6 +35,7 @@ var noColors bool
 const (
 	CharTrack  = " ▶"
 	CharFinish = " ■"
+	CharPause  = " ❚"
 	CharErase  = " ◀"
 	CharError  = " ▲"
 	CharInfo   = " ●"
@@ -41,9 +43,10 @@ const (
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "zeit",
-	Short: "Command line Zeiterfassung",
-	Long:  `A command line time tracker.`,
+	Use:              "zeit",
+	Short:            "Command line Zeiterfassung",
+	Long:             `A command line time tracker.`,
+	PersistentPreRun: prepareDatabase,
 }
 
 func Execute() {
@@ -59,6 +62,27 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&noColors, "no-colors", false, "Do not use colors in output")
 }
 
+// prepareDatabase runs before every command. It applies pending migrations, unless
+// 'ZEIT_AUTO_MIGRATE=false' is set, and adds the recurring activities that are over by now.
+// Help, version and shell completion leave the database alone, pressing Tab must not write to it.
+func prepareDatabase(cmd *cobra.Command, args []string) {
+	switch cmd.Name() {
+	case "help", "version", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return
+	}
+	if database == nil {
+		return
+	}
+	if autoMigrate() {
+		_, err := database.Migrate()
+		if err != nil {
+			fmt.Printf("%s error while migrating the database. Error: %s\n", CharError, err.Error())
+			os.Exit(1)
+		}
+	}
+	applyRecurring()
+}
+
 func initConfig() {
 	if noColors {
 		color.Disable()
@@ -0,0 +1,185 @@
+package z
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+var ruleDir string
+var ruleRemote string
+
+var ruleCmd = &cobra.Command{
+	Use:   "rule",
+	Short: "Manage rules detecting the project",
+	Long: `Manage the rules that pick the project when 'zeit track' is run without --project.
+
+A repository mapped via 'zeit repo' picks its project first, then the rules
+matching the current directory and then those matching a remote of its
+repository, each in the order they were added.
+'zeit track --explain' shows which one picks the project.`,
+}
+
+var ruleAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a rule",
+	Long: `Add a rule picking the project for a directory or a git remote.
+
+--dir is a glob matched against the current directory and each of its parents,
+e.g. '~/src/acme/*' for every repository below ~/src/acme.
+In --remote, '*' matches any text, e.g. '*github.com/acme/*'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if project == "" {
+			fmt.Printf("%s Can not add a rule without a project.\nPlease assign a project via --project\n", CharError)
+			os.Exit(1)
+		}
+		if (ruleDir == "") == (ruleRemote == "") {
+			fmt.Printf("%s Please give either --dir or --remote\n", CharError)
+			os.Exit(1)
+		}
+		rule := ProjectRule{Kind: RuleRemote, Pattern: ruleRemote, Project: project}
+		if ruleDir != "" {
+			rule.Kind = RuleDir
+			rule.Pattern = expandDirPattern(ruleDir)
+		}
+		err := ValidateProjectRule(rule)
+		if err != nil {
+			fmt.Printf("%s could not parse the pattern '%s'. Error: %s\n", CharError, rule.Pattern, err.Error())
+			os.Exit(1)
+		}
+		err = database.AddProjectRule(&rule)
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s added %s\n", CharInfo, getOutputForProjectRule(rule))
+	},
+}
+
+var ruleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the rules",
+	Run: func(cmd *cobra.Command, args []string) {
+		rules, err := database.GetProjectRules()
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		if len(rules) == 0 {
+			fmt.Printf("%s no rules yet, add one with 'zeit rule add'\n", CharInfo)
+			return
+		}
+		for _, rule := range rules {
+			fmt.Printf("%s\n", getOutputForProjectRule(rule))
+		}
+	},
+}
+
+var ruleRemoveCmd = &cobra.Command{
+	Use:   "remove [id]",
+	Short: "Remove a rule",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			fmt.Printf("%s %s", CharError, "Please provide a valid number")
+			os.Exit(1)
+		}
+		err = database.DeleteProjectRule(id)
+		if errors.Is(err, sql.ErrNoRows) {
+			fmt.Printf("%s there is no rule %d.\n", CharError, id)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s removed rule %s\n", CharErase, color.FgLightWhite.Render(id))
+	},
+}
+
+// expandDirPattern makes a --dir pattern absolute, '~' stands for the home directory.
+func expandDirPattern(pattern string) string {
+	if pattern == "~" || strings.HasPrefix(pattern, "~/") {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			pattern = home + pattern[1:]
+		}
+	}
+	absolute, err := filepath.Abs(pattern)
+	if err != nil {
+		return pattern
+	}
+	return absolute
+}
+
+func getOutputForProjectRule(rule ProjectRule) string {
+	return fmt.Sprintf("%s %s %s → %s",
+		color.FgGray.Render(rule.ID),
+		rule.Kind,
+		rule.Pattern,
+		color.FgLightWhite.Render(rule.Project))
+}
+
+// detectProject fills --project from the current directory if it was not given.
+// With explain it prints what picked the project and stops zeit.
+func detectProject(explain bool) {
+	if project != "" {
+		if explain {
+			fmt.Printf("%s the project %s was given, no rule is used.\n", CharInfo, color.FgLightWhite.Render(project))
+			os.Exit(0)
+		}
+		return
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+	detection, err := DetectProject(dir)
+	if err != nil {
+		fmt.Printf("%s could not detect the project. Error: %s\n", CharError, err.Error())
+		os.Exit(1)
+	}
+	if !explain {
+		if detection != nil {
+			project = detection.Project
+		}
+		return
+	}
+	switch {
+	case detection == nil:
+		fmt.Printf("%s no rule matches %s, see 'zeit rule list'.\n", CharInfo, dir)
+	case detection.Rule == nil:
+		fmt.Printf("%s the repository %s is mapped to %s\n", CharInfo, detection.Matched, color.FgLightWhite.Render(detection.Project))
+	default:
+		fmt.Printf("%s rule %s matches %s\n", CharInfo, getOutputForProjectRule(*detection.Rule), detection.Matched)
+	}
+	os.Exit(0)
+}
+
+func init() {
+	rootCmd.AddCommand(ruleCmd)
+	ruleCmd.AddCommand(ruleAddCmd)
+	ruleCmd.AddCommand(ruleListCmd)
+	ruleCmd.AddCommand(ruleRemoveCmd)
+	ruleAddCmd.Flags().StringVarP(&project, "project", "p", "", "Project the rule picks")
+	ruleAddCmd.Flags().StringVar(&ruleDir, "dir", "", "Glob matching the directories of the project, e.g. ~/src/acme/*")
+	ruleAddCmd.Flags().StringVar(&ruleRemote, "remote", "", "Pattern matching the git remote URLs of the project, e.g. *github.com/acme/*")
+
+	registerProjectTaskCompletion(ruleAddCmd, false)
+
+	var err error
+	database, err = InitDB()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+}
@@ -2,27 +2,75 @@ package z
 
 import (
 	"fmt"
+	"github.com/gookit/color"
+	"github.com/jinzhu/now"
 	"github.com/spf13/cobra"
 	"os"
 	"strings"
 	"time"
-	// "github.com/shopspring/decimal"
-	// "github.com/gookit/color"
+
+	"github.com/shopspring/decimal"
 )
 
+var statsConcurrent string
+
 var statsCmd = &cobra.Command{
 	Use:   "stats",
 	Short: "Display activity statistics",
 	Long:  "Display statistics on all tracked activities.",
 	Run: func(cmd *cobra.Command, args []string) {
 
-		entries, err := database.GetAllEntries()
+		var err error
+		var sinceTime time.Time
+		var untilTime time.Time
+
+		if since != "" {
+			sinceTime, err = now.Parse(since)
+			if err != nil {
+				fmt.Printf("%s %+v\n", CharError, err)
+				os.Exit(1)
+			}
+		}
+
+		if until != "" {
+			untilTime, err = now.Parse(until)
+			if err != nil {
+				fmt.Printf("%s %+v\n", CharError, err)
+				os.Exit(1)
+			}
+		}
+
+		entries, err := database.QueryEntries(EntryQuery{
+			Project: project,
+			Task:    task,
+			Since:   sinceTime,
+			Until:   untilTime,
+		})
 		if err != nil {
 			fmt.Printf("%s %+v\n", CharError, err)
 			os.Exit(1)
 		}
 
-		cal, _ := NewCalendar(entries)
+		var shares map[int64]decimal.Decimal
+		switch statsConcurrent {
+		case ConcurrentFull:
+		case ConcurrentSplit:
+			// Time is shared with every entry running at once, not only the ones that are shown.
+			allEntries := entries
+			if project != "" || task != "" {
+				allEntries, err = database.QueryEntries(EntryQuery{Since: sinceTime, Until: untilTime})
+				if err != nil {
+					fmt.Printf("%s %+v\n", CharError, err)
+					os.Exit(1)
+				}
+			}
+			shares = ConcurrentShares(allEntries)
+		default:
+			fmt.Printf("%s --concurrent has to be either full or split.\n", CharError)
+			os.Exit(1)
+		}
+
+		cal, _ := NewCalendar(entries, shares)
 
 		weekMinus0 := time.Now()
 		monthMinus0, weeknumberMinus0 := GetISOWeekInMonth(weekMinus0)
@@ -43,12 +91,45 @@ var statsCmd = &cobra.Command{
 		}
 		fmt.Printf("%s\n\n\n", OutputAppendRight(thisWeek, previousWeek, 16))
 		fmt.Printf("%s\n", cal.GetOutputForDistribution())
+
+		pomodorosSince := sinceTime
+		if pomodorosSince.IsZero() {
+			pomodorosSince = now.BeginningOfDay().AddDate(0, 0, -13)
+		}
+		pomodoros, err := database.GetPomodorosPerDay(EntryQuery{
+			Project: project,
+			Task:    task,
+			Since:   pomodorosSince,
+			Until:   untilTime,
+		})
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		if len(pomodoros) > 0 {
+			fmt.Printf("%s\n", GetOutputForPomodoros(pomodoros))
+		}
 	},
 }
 
+func GetOutputForPomodoros(pomodoros []PomodorosOfDay) string {
+	var output = ""
+	for _, day := range pomodoros {
+		output = fmt.Sprintf("%s%s  %s %d\n", output, day.Date, color.FgLightRed.Render(strings.Repeat("●", day.Count)), day.Count)
+	}
+	return fmt.Sprintf("POMODOROS\n\n%s", output)
+}
+
 func init() {
 	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().StringVar(&since, "since", "", "Date/time to start the statistics from")
+	statsCmd.Flags().StringVar(&until, "until", "", "Date/time to compute the statistics until")
+	statsCmd.Flags().StringVarP(&project, "project", "p", "", "Project to compute the statistics for")
+	statsCmd.Flags().StringVarP(&task, "task", "t", "", "Task to compute the statistics for")
+	statsCmd.Flags().StringVar(&statsConcurrent, "concurrent", ConcurrentFull, "How time tracked by several timers at once counts: full for each of them or split between them")
 	statsCmd.Flags().BoolVar(&fractional, "decimal", true, "Show fractional hours in decimal format instead of minutes")
+	registerProjectTaskCompletion(statsCmd, false)
+
 	var err error
 	database, err = InitDB()
 	if err != nil {
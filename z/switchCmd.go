@@ -0,0 +1,113 @@
+package z
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var switchFrom int64
+
+var switchCmd = &cobra.Command{
+	Use:   "switch ([flags]) [@alias]",
+	Short: "Switch to a new activity",
+	Long: `Finish the currently running activity and start tracking a new one at exactly the same time.
+
+Both happen at once, so there is no gap between the two activities and
+nothing is changed if the new activity can not be started.
+If --project is not given, the project of the running activity is kept.
+With parallel timers, the running activity to switch from is picked by its id via --from.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		applyAliasArg(args)
+		// Without a running entry there is nothing to finish, switching then is the same as tracking.
+		runningEntry := selectEntryToSwitchFrom(switchFrom)
+
+		switchProject := project
+		if switchProject == "" && runningEntry != nil {
+			switchProject = runningEntry.Project
+		}
+		if task == "" {
+			fmt.Printf("%s Can not switch to an empty task.\nPlease assign a task via --task\n", CharError)
+			os.Exit(1)
+		}
+		if switchProject == "" {
+			fmt.Printf("%s Can not track empty project.\nPlease assign a project via --project\n", CharError)
+			os.Exit(1)
+		}
+
+		switchTime := time.Now().Truncate(0)
+		if begin != "" {
+			var err error
+			switchTime, err = ParseTime(begin)
+			if err != nil {
+				fmt.Printf("%s could not parse --begin '%s'. Error: %s\n", CharError, begin, err.Error())
+				os.Exit(1)
+			}
+		}
+
+		newEntry := NewEntry(switchProject, task)
+		newEntry.Begin = switchTime
+		newEntry.SetDateFromBegining()
+		// Switching away from a parallel timer leaves the others running, so the new one runs in parallel too.
+		newEntry.Parallel = runningEntry != nil && runningEntry.Parallel
+		if notes != "" {
+			newEntry.Notes = notes
+		}
+
+		if runningEntry != nil && !runningEntry.Begin.Before(switchTime) {
+			fmt.Printf("%s --begin has to be after the running task began at %s.\n", CharError, runningEntry.Begin.Format("2006-01-02 15:04"))
+			os.Exit(1)
+		}
+		switchTo(runningEntry, &newEntry)
+	},
+}
+
+// switchTo finishes the running entry when newEntry begins and starts newEntry, or only starts it if nothing runs.
+func switchTo(runningEntry *Entry, newEntry *Entry) {
+	if runningEntry == nil {
+		overlapping, err := database.AddEntry(newEntry, true)
+		if err != nil {
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		warnOverlaps(*newEntry, overlapping)
+		fmt.Printf("%s no task was running.\n", CharInfo)
+		fmt.Println(newEntry.GetStartTrackingStr())
+		return
+	}
+
+	runningEntry.Finish = newEntry.Begin
+	runningEntry.Running = false
+	runningEntry.Hours = runningEntry.GetDuration()
+
+	overlapping, err := database.SwitchEntry(*runningEntry, newEntry)
+	if err != nil {
+		fmt.Printf("%s could not switch tasks, nothing was changed. Error: %s\n", CharError, err.Error())
+		os.Exit(1)
+	}
+	warnOverlaps(*newEntry, overlapping)
+	fmt.Print(runningEntry.GetOutputForFinish())
+	fmt.Println(newEntry.GetStartTrackingStr())
+}
+
+func init() {
+	rootCmd.AddCommand(switchCmd)
+	switchCmd.Flags().StringVarP(&begin, "begin", "b", "", "Time the switch should happen at\n\nEither in the formats 16:00 / 4:00PM \nor relative to the current time, \ne.g. -0:15 (now minus 15 minutes), +1.50 (now plus 1:30h).")
+	switchCmd.Flags().StringVarP(&project, "project", "p", "", "Project to be assigned, defaults to the one of the running activity")
+	switchCmd.Flags().StringVarP(&task, "task", "t", "", "Task to be assigned")
+	switchCmd.Flags().StringVarP(&notes, "notes", "n", "", "Activity notes")
+	switchCmd.Flags().Int64Var(&switchFrom, "from", 0, "Id of the running activity to finish, if several are running")
+
+	registerProjectTaskCompletion(switchCmd, false)
+	switchCmd.ValidArgsFunction = completeAliasArg
+
+	var err error
+	database, err = InitDB()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,43 @@
+package z
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Escape sequences used by the commands that keep running in the foreground.
+const (
+	termHideCursor = "\033[?25l"
+	termShowCursor = "\033[?25h"
+	termClearLine  = "\r\033[2K"
+)
+
+// startLiveLine prepares the terminal for a line that is redrawn in place with drawLiveLine.
+func startLiveLine() {
+	fmt.Print(termHideCursor)
+}
+
+func drawLiveLine(text string) {
+	fmt.Print(termClearLine + text)
+}
+
+// stopLiveLine clears the line and gives the cursor back, the terminal is left the way it was found.
+func stopLiveLine() {
+	fmt.Print(termClearLine + termShowCursor)
+}
+
+// notifyInterrupt delivers Ctrl-C and SIGTERM on the returned channel instead of killing zeit,
+// so whatever is tracked in the foreground can be finished first.
+func notifyInterrupt() (chan os.Signal, func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	return signals, func() { signal.Stop(signals) }
+}
+
+func fmtCountdown(remaining time.Duration) string {
+	remaining = remaining.Round(time.Second)
+	return fmt.Sprintf("%02d:%02d", int(remaining.Minutes()), int(remaining.Seconds())%60)
+}
@@ -4,58 +4,254 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"github.com/gookit/color"
 	"github.com/spf13/cobra"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
+var trackForeground bool
+var trackExplain bool
+
 var trackCmd = &cobra.Command{
-	Use:   "track",
+	Use:   "track ([flags]) [@alias]",
 	Short: "Tracking time",
 	Long:  "Track new activity, which can either be kept running until 'finish' is being called or parameterized to be a finished activity.",
+	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-
-		entry, err := database.GetRunningEntry()
-		if err != nil {
-			switch {
-			case errors.Is(err, sql.ErrNoRows):
-				//ignore this, as there are no tasks running, which is what we want.
-			default:
-				fmt.Printf("%s %+v\n", CharError, err)
-				os.Exit(1)
-			}
-		}
-		if entry != nil {
-			fmt.Printf("%s A task is already running, you have to finish it first before you start a new one.\n\nType 'zeit finish' to do so.", CharError)
+		applyAliasArg(args)
+		detectProject(trackExplain)
+		if finish != "" && trackForeground {
+			fmt.Printf("%s --foreground can only be used for a running activity, not together with --finish.\n", CharError)
 			os.Exit(1)
 		}
+		// A finished activity can be added at any time, only a running one has to wait for the current one.
+		if finish == "" && !parallelEnabled() {
+			exitIfEntryRunning()
+		}
 		if task == "" {
 			fmt.Printf("%s Can not track empty task.\nPlease assign a task via --task to track\n", CharError)
+			os.Exit(1)
 		}
 		if project == "" {
-			fmt.Printf("%s Can not track empty project.\nPlease assign a project via --project\n", CharError)
+			fmt.Printf("%s Can not track empty project.\nPlease assign a project via --project or add a rule for this directory via 'zeit rule add'\n", CharError)
 			os.Exit(1)
 		}
 		newEntry := NewEntry(project, task)
+		newEntry.Parallel = parallelEnabled()
 		if notes != "" {
 			newEntry.Notes = notes
 		}
-		err = database.AddEntry(&newEntry, true)
+		_, err := newEntry.SetBeginFromString(begin)
 		if err != nil {
-			fmt.Printf("something went wrong. Error: %s", err.Error())
+			fmt.Printf("%s could not parse --begin '%s'. Error: %s\n", CharError, begin, err.Error())
 			os.Exit(1)
 		}
+		newEntry.SetDateFromBegining()
+
+		if finish != "" {
+			_, err = newEntry.SetFinishFromString(finish)
+			if err != nil {
+				fmt.Printf("%s could not parse --finish '%s'. Error: %s\n", CharError, finish, err.Error())
+				os.Exit(1)
+			}
+			if !newEntry.IsFinishedAfterBegan() {
+				fmt.Printf("%s --finish has to be after --begin.\n", CharError)
+				os.Exit(1)
+			}
+			newEntry.Hours = newEntry.GetDuration()
+			overlapping, err := database.AddEntry(&newEntry, false)
+			if err != nil {
+				fmt.Printf("%s %+v\n", CharError, err)
+				os.Exit(1)
+			}
+			warnOverlaps(newEntry, overlapping)
+			fmt.Print(newEntry.GetOutputForTrack(false, false))
+			warnBudget(newEntry)
+			return
+		}
 
-		fmt.Print(newEntry.GetStartTrackingStr())
+		startEntry(&newEntry)
+		fmt.Println(newEntry.GetStartTrackingStr())
+		warnBudget(newEntry)
+		if trackForeground {
+			trackInForeground(newEntry.ID)
+		}
 	},
 }
 
+// trackInForeground shows the running entry with its elapsed time until it is finished,
+// either somewhere else or by Ctrl-C/SIGTERM, which finishes it at that moment.
+func trackInForeground(id int64) {
+	signals, stop := notifyInterrupt()
+	defer stop()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	startLiveLine()
+	for {
+		// Reloading keeps up with pauses, edits or a 'zeit finish' from another terminal.
+		entry, err := database.GetEntry(id)
+		if err != nil {
+			stopLiveLine()
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+		if !entry.Running {
+			stopLiveLine()
+			fmt.Print(entry.GetOutputForFinish())
+			return
+		}
+		output := strings.TrimSuffix(entry.GetOutputForTrack(true, true), "\n")
+		if entry.IsPaused() {
+			output = fmt.Sprintf("%s %s", output, color.FgLightYellow.Render("[paused]"))
+		}
+		drawLiveLine(output)
+
+		select {
+		case <-ticker.C:
+		case <-signals:
+			stopLiveLine()
+			entry.SetFinish()
+			entry.Hours = entry.GetDuration()
+			err = database.AddFinishToEntry(*entry)
+			if err != nil {
+				fmt.Printf("%s something went wrong updating the entry. Error: %s\n", CharError, err.Error())
+				os.Exit(1)
+			}
+			fmt.Print(entry.GetOutputForFinish())
+			return
+		}
+	}
+}
+
+// exitIfEntryRunning stops zeit if a task is running already, only one can be tracked at a time.
+func exitIfEntryRunning() {
+	entry, err := database.GetRunningEntry()
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			//ignore this, as there are no tasks running, which is what we want.
+		default:
+			fmt.Printf("%s %+v\n", CharError, err)
+			os.Exit(1)
+		}
+	}
+	if entry != nil {
+		fmt.Printf("%s A task is already running, you have to finish it first before you start a new one.\n\nType 'zeit finish' to do so.\n", CharError)
+		os.Exit(1)
+	}
+}
+
+// runningEntriesFor returns the running entries matching the id in args and --project/--task.
+func runningEntriesFor(args []string) []Entry {
+	runningEntries, err := database.GetRunningEntries()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+	var id int64
+	if len(args) > 0 {
+		id, err = strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			fmt.Printf("%s %s\n", CharError, "Please provide a valid number")
+			os.Exit(1)
+		}
+	}
+	var matching []Entry
+	for _, entry := range runningEntries {
+		if (id == 0 || entry.ID == id) && (project == "" || entry.Project == project) && (task == "" || entry.Task == task) {
+			matching = append(matching, entry)
+		}
+	}
+	if id != 0 && len(matching) == 0 {
+		fmt.Printf("%s entry %d is not running.\n", CharError, id)
+		os.Exit(1)
+	}
+	return matching
+}
+
+// selectRunningEntry returns the single running entry a command works on, nil if none is running.
+// With parallel timers several can run, then one has to be picked by its id or --project/--task.
+func selectRunningEntry(args []string) *Entry {
+	matching := runningEntriesFor(args)
+	switch len(matching) {
+	case 0:
+		return nil
+	case 1:
+		return &matching[0]
+	}
+	exitPickRunningEntry(matching, "by its id or with --project/--task")
+	return nil
+}
+
+// selectEntryToSwitchFrom returns the running entry 'switch' and 'exec --switch' finish, nil if none is running.
+// There --project and --task name the new activity, so one of several running entries is picked by its id via --from.
+func selectEntryToSwitchFrom(from int64) *Entry {
+	runningEntries, err := database.GetRunningEntries()
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+	if from != 0 {
+		for i := range runningEntries {
+			if runningEntries[i].ID == from {
+				return &runningEntries[i]
+			}
+		}
+		fmt.Printf("%s entry %d is not running.\n", CharError, from)
+		os.Exit(1)
+	}
+	switch len(runningEntries) {
+	case 0:
+		return nil
+	case 1:
+		return &runningEntries[0]
+	}
+	exitPickRunningEntry(runningEntries, "to switch from with --from <id>")
+	return nil
+}
+
+// exitPickRunningEntry stops zeit because several entries are running and the command can not tell which one is meant.
+func exitPickRunningEntry(entries []Entry, how string) {
+	fmt.Printf("%s %d tasks are running, pick one %s:\n", CharError, len(entries), how)
+	for _, entry := range entries {
+		fmt.Printf("%s\n", entry.GetOutput(false))
+	}
+	os.Exit(1)
+}
+
+// startEntry adds entry as the running one and stops zeit if that fails.
+func startEntry(entry *Entry) {
+	overlapping, err := database.AddEntry(entry, true)
+	if errors.Is(err, ErrEntryAlreadyRunning) {
+		// Another zeit started a task between our check and the insert.
+		fmt.Printf("%s A task was started in the meantime, you have to finish it first before you start a new one.\n\nType 'zeit finish' to do so.\n", CharError)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Printf("%s %+v\n", CharError, err)
+		os.Exit(1)
+	}
+	warnOverlaps(*entry, overlapping)
+}
+
 func init() {
 	rootCmd.AddCommand(trackCmd)
 	trackCmd.Flags().StringVarP(&begin, "begin", "b", "", "Time the activity should begin at\n\nEither in the formats 16:00 / 4:00PM \nor relative to the current time, \ne.g. -0:15 (now minus 15 minutes), +1.50 (now plus 1:30h).")
 	trackCmd.Flags().StringVarP(&finish, "finish", "s", "", "Time the activity should finish at\n\nEither in the formats 16:00 / 4:00PM \nor relative to the current time, \ne.g. -0:15 (now minus 15 minutes), +1.50 (now plus 1:30h).\nMust be after --begin time.")
-	trackCmd.Flags().StringVarP(&project, "project", "p", "", "Project to be assigned")
+	trackCmd.Flags().StringVarP(&project, "project", "p", "", "Project to be assigned, detected from the current directory if not given")
 	trackCmd.Flags().StringVarP(&task, "task", "t", "", "Task to be assigned")
 	trackCmd.Flags().StringVarP(&notes, "notes", "n", "", "Activity notes")
+	trackCmd.Flags().StringVar(&overlapPolicy, "overlap", "", "What to do if the activity overlaps others: warn, reject or ignore (default ZEIT_OVERLAP or warn)")
+	trackCmd.Flags().BoolVar(&parallel, "parallel", false, "Start the activity even if others are running (or set ZEIT_PARALLEL=true)")
+	trackCmd.Flags().BoolVar(&trackExplain, "explain", false, "Only show which rule picks the project for the current directory")
+	trackCmd.Flags().BoolVar(&trackForeground, "foreground", false, "Keep running and show the elapsed time, Ctrl-C finishes the activity")
+
+	registerProjectTaskCompletion(trackCmd, false)
+	trackCmd.ValidArgsFunction = completeAliasArg
 
 	var err error
 	database, err = InitDB()
@@ -1,42 +1,69 @@
 package z
 
 import (
-	"database/sql"
-	"errors"
 	"fmt"
+	"github.com/gookit/color"
 	"github.com/spf13/cobra"
 	"os"
+	"time"
 )
 
+var trackingWatch bool
+
 var trackingCmd = &cobra.Command{
-	Use:   "tracking",
+	Use:   "tracking ([flags]) [id]",
 	Short: "Currently tracking activity",
-	Long:  "Show currently tracking activity.",
+	Long:  "Show currently tracking activity.\n\nWith parallel timers running, all of them are shown unless one is picked by its id or with --project/--task.",
+	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-
-		entry, err := database.GetRunningEntry()
-		if err != nil {
-			switch {
-			case errors.Is(err, sql.ErrNoRows):
+		if trackingWatch {
+			entry := selectRunningEntry(args)
+			if entry == nil {
 				fmt.Printf("%s No task currently running.", CharFinish)
 				os.Exit(1)
-			default:
-				fmt.Printf("something went wrong getting current runnign entries. Error: %s", err.Error())
-				os.Exit(1)
 			}
-
+			trackInForeground(entry.ID)
+			return
 		}
-		// Kind of is redundant but I keep it for now.
-		if entry == nil {
+
+		entries := runningEntriesFor(args)
+		if len(entries) == 0 {
 			fmt.Printf("%s No task currently running.", CharFinish)
 			os.Exit(1)
 		}
-		fmt.Printf("%s %s", CharTrack, entry.GetOutputStrShort())
+		for _, entry := range entries {
+			printTracking(entry)
+		}
 	},
 }
 
+func printTracking(entry Entry) {
+	entry.Hours = entry.GetDuration().Round(2)
+	fmt.Printf("%s %s\n", CharTrack, entry.GetOutputStrShort())
+	if pause := entry.CurrentPause(); pause != nil {
+		fmt.Printf("%s paused since %s for %sh\n", CharPause,
+			color.FgLightWhite.Render(pause.Begin.Format("15:04")),
+			color.FgLightWhite.Render(fmtDuration(time.Since(pause.Begin))))
+	}
+	if entry.IsForgotten(maxRunningDuration()) {
+		fmt.Printf("%s running for longer than %sh, was it forgotten? 'zeit finish --cap' finishes it at %s.\n", CharError,
+			fmtDuration(maxRunningDuration()),
+			color.FgLightWhite.Render(entry.SuggestedCap(maxRunningDuration()).Format("2006-01-02 15:04")))
+	}
+	if len(entry.Pauses) > 0 {
+		fmt.Printf("%s %sh paused in total\n", CharInfo, color.FgLightWhite.Render(fmtDuration(entry.PausedDuration())))
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(trackingCmd)
+	trackingCmd.Flags().StringVarP(&project, "project", "p", "", "Only show the running activity of this project")
+	trackingCmd.Flags().StringVarP(&task, "task", "t", "", "Only show the running activity with this task")
+	trackingCmd.Flags().DurationVar(&maxRunning, "max-running", 0, "Running activities longer than this count as forgotten (default ZEIT_MAX_RUNNING or 12h)")
+	trackingCmd.Flags().BoolVar(&trackingWatch, "watch", false, "Keep running and show the elapsed time, Ctrl-C finishes the activity")
+
+	registerProjectTaskCompletion(trackingCmd, false)
+	trackingCmd.ValidArgsFunction = completeRunningEntryID
 
 	var err error
 	database, err = InitDB()
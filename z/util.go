@@ -1,7 +1,10 @@
 package z
 
 import (
+	"bufio"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -26,3 +29,19 @@ func fmtHours(hours decimal.Decimal) string {
 				Floor())
 	}
 }
+
+// ask prints the question and returns the trimmed answer typed on the terminal.
+func ask(question string) string {
+	fmt.Printf("%s %s ", CharMore, question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Println()
+	}
+	return strings.TrimSpace(answer)
+}
+
+// confirm asks a yes/no question on the terminal, anything but 'y'/'yes' counts as no.
+func confirm(question string) bool {
+	answer := strings.ToLower(ask(question + " [y/N]"))
+	return answer == "y" || answer == "yes"
+}